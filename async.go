@@ -0,0 +1,159 @@
+// Copyright 2025 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spacetest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"sync"
+
+	. "github.com/onsi/ginkgo/v2" //nolint:staticcheck // ST1001 rule does not apply
+	. "github.com/onsi/gomega"    //nolint:staticcheck // ST1001 rule does not apply
+)
+
+// Execution is a handle to a function executing asynchronously in a set of
+// namespaces, as started by [Go].
+type Execution struct {
+	done     chan struct{} // closed when fn has finished or ctx expired.
+	finished chan struct{} // closed when fn has finished.
+	mu       sync.Mutex
+	err      error
+	panicked any
+}
+
+// Go starts the passed fn asynchronously on a dedicated go routine and its
+// dedicated OS-level thread while attached to the specified namespace(s), and
+// otherwise defaulting to the caller's currently attached namespaces. In
+// contrast to [Execute], Go always uses a separate OS-level thread, even if no
+// mount namespace has been specified.
+//
+// Go returns only after the dedicated OS-level thread has been successfully
+// attached to the namespaces, and fails the current test otherwise. Same as
+// with Execute, switching into a different user namespace is not possible.
+//
+// Use the returned [Execution] to wait for fn to finish. If the passed context
+// expires while fn is still running, the Execution gets done with an error,
+// but fn is left running. As its dedicated OS-level thread never gets unlocked,
+// it is tainted and thrown away when fn finally returns, instead of returning
+// the thread to the Go scheduler.
+func Go(ctx context.Context, fn func(), nsref NamespaceRef, nsrefs ...NamespaceRef) *Execution {
+	GinkgoHelper()
+
+	mntnsfd, othernsfds, err := sortOut(append([]NamespaceRef{nsref}, nsrefs...))
+	if errors.Is(err, errUserNamespace) {
+		err = fmt.Errorf("cannot Go() %w", err)
+	}
+	Expect(err).NotTo(HaveOccurred())
+
+	runtime.LockOSThread()
+	pickupfds, err := pickup(othernsfds)
+	runtime.UnlockOSThread()
+	defer closeAll(pickupfds)
+	Expect(err).NotTo(HaveOccurred())
+
+	x := &Execution{
+		done:     make(chan struct{}),
+		finished: make(chan struct{}),
+	}
+	setupCh := make(chan error)
+	go func() {
+		runtime.LockOSThread() // never unlock, as this thread is going to be tainted
+
+		if err := attach(mntnsfd, append(othernsfds, pickupfds...)); err != nil {
+			setupCh <- err
+			return
+		}
+		setupCh <- nil
+
+		defer func() { x.finish(recover()) }()
+		fn()
+	}()
+	Expect(<-setupCh).To(Succeed())
+
+	go x.watch(ctx)
+	return x
+}
+
+// finish records fn having finished, panicking with the passed value if
+// non-nil.
+func (x *Execution) finish(panicked any) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	close(x.finished)
+	select {
+	case <-x.done:
+		// the context already expired, so nobody will be interested anymore.
+		return
+	default:
+	}
+	if panicked != nil {
+		x.panicked = panicked
+		x.err = fmt.Errorf("fn panicked: %v", panicked)
+	}
+	close(x.done)
+}
+
+// watch the passed context and mark the execution as done with an error in
+// case the context expires before fn has finished.
+func (x *Execution) watch(ctx context.Context) {
+	select {
+	case <-x.finished:
+	case <-ctx.Done():
+		x.mu.Lock()
+		defer x.mu.Unlock()
+		select {
+		case <-x.finished:
+			return
+		default:
+		}
+		x.err = fmt.Errorf("fn still running when context expired: %w", context.Cause(ctx))
+		close(x.done)
+	}
+}
+
+// Done returns a channel that gets closed when fn has finished, or when the
+// context passed to [Go] expired while fn was still running.
+func (x *Execution) Done() <-chan struct{} {
+	return x.done
+}
+
+// Err returns nil as long as the Execution isn't done yet, or when fn finished
+// without panicking. Otherwise, Err returns an error describing the panic, or
+// that the context expired while fn was still running.
+func (x *Execution) Err() error {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	select {
+	case <-x.done:
+		return x.err
+	default:
+		return nil
+	}
+}
+
+// Wait for the Execution to be done, returning [Execution.Err]. If fn panicked,
+// Wait rethrows the panic on the caller's go routine, so that Gomega failures
+// inside fn fail the current test.
+func (x *Execution) Wait() error {
+	<-x.done
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	if x.panicked != nil {
+		panic(x.panicked)
+	}
+	return x.err
+}
@@ -0,0 +1,102 @@
+// Copyright 2025 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spacetest
+
+import (
+	"context"
+	"os"
+	"runtime"
+	"time"
+
+	"golang.org/x/sys/unix"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gleak"
+	. "github.com/thediveo/fdooze"
+)
+
+var _ = Describe("asynchronous execution", func() {
+
+	BeforeEach(func() {
+		if os.Getuid() != 0 {
+			Skip("needs root")
+		}
+		goodfds := Filedescriptors()
+		goodgos := Goroutines()
+		DeferCleanup(func() {
+			Eventually(Goroutines).Within(2 * time.Second).ProbeEvery(100 * time.Millisecond).
+				ShouldNot(HaveLeaked(goodgos))
+			Expect(Filedescriptors()).NotTo(HaveLeakedFds(goodfds))
+		})
+	})
+
+	It("executes in namespaces on a separate thread", func(ctx context.Context) {
+		runtime.LockOSThread()
+		defer runtime.UnlockOSThread()
+
+		netns := NewTransientNamespace(unix.CLONE_NEWNET)
+		tid := unix.Gettid()
+		var fntid int
+		x := Go(ctx, func() {
+			fntid = unix.Gettid()
+			Expect(netns).To(BeCurrentNamespace())
+			Expect("/proc/thread-self/ns/mnt").To(BeSameNamespaceAs("/proc/self/ns/mnt"))
+		}, netns)
+		Eventually(x.Done()).Should(BeClosed())
+		Expect(x.Err()).To(Succeed())
+		Expect(x.Wait()).To(Succeed())
+		Expect(fntid).NotTo(Equal(tid))
+	})
+
+	It("executes in a mount namespace", func(ctx context.Context) {
+		mntns := CurrentNamespace(unix.CLONE_NEWNS)
+		netns := NewTransientNamespace(unix.CLONE_NEWNET)
+		Expect(Go(ctx, func() {
+			Expect(mntns).To(BeCurrentNamespace())
+			Expect(netns).To(BeCurrentNamespace())
+		}, mntns, netns).Wait()).To(Succeed())
+	})
+
+	It("reports when the context expires with fn still running", func(ctx context.Context) {
+		ctx, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+		defer cancel()
+		release := make(chan struct{})
+		x := Go(ctx, func() { <-release }, CurrentNamespace(unix.CLONE_NEWNET))
+		Expect(x.Err()).To(Succeed())
+		Consistently(x.Done()).Within(50 * time.Millisecond).ShouldNot(BeClosed())
+		Expect(x.Wait()).To(And(
+			MatchError(ContainSubstring("fn still running when context expired")),
+			MatchError(context.DeadlineExceeded)))
+		close(release)
+	})
+
+	It("passes panics on to the caller", func(ctx context.Context) {
+		x := Go(ctx, func() { panic("D'oh!") }, CurrentNamespace(unix.CLONE_NEWNET))
+		Eventually(x.Done()).Should(BeClosed())
+		Expect(x.Err()).To(MatchError("fn panicked: D'oh!"))
+		Expect(func() { _ = x.Wait() }).To(PanicWith("D'oh!"))
+	})
+
+	It("fails when unable to switch namespaces", func(ctx context.Context) {
+		Expect(InterceptGomegaFailure(func() {
+			_ = Go(ctx, func() {}, Fd(-1))
+		})).To(MatchError(ContainSubstring("invalid namespace reference")))
+		Expect(InterceptGomegaFailure(func() {
+			_ = Go(ctx, func() {}, CurrentNamespace(unix.CLONE_NEWUSER))
+		})).To(MatchError(ContainSubstring("cannot Go() in different user namespace")))
+	})
+
+})
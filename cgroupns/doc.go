@@ -0,0 +1,53 @@
+/*
+Package cgroupns supports running unit tests in transient cgroup namespaces
+that are rooted at their own fresh and throw-away cgroup v2 subtree.
+
+Simply unsharing a new cgroup namespace (such as using
+[github.com/thediveo/spacetest.NewTransient] with [unix.CLONE_NEWCGROUP]) roots
+the new cgroup namespace at the current cgroup of the unsharing thread. In
+consequence, the view into the cgroup hierarchy from inside the new cgroup
+namespace often looks just like the host's, especially when the test process
+sits in the root cgroup, as is often the case in containers.
+
+In contrast, [NewTransient] from this package first creates a new child cgroup
+below the cgroup of the test process and then moves a dedicated idler thread
+into it. Only then this idler thread unshares its cgroup namespace, so that the
+new cgroup namespace is rooted at the new child cgroup. Finally, the idler
+thread creates a transient mount namespace and mounts a fresh cgroup2
+filesystem instance in it. Inside this mount namespace, the cgroup2 mount point
+now shows the throw-away cgroup as the root of the cgroup hierarchy.
+
+# Usage
+
+	import (
+	    "github.com/thediveo/spacetest"
+	    "github.com/thediveo/spacetest/cgroupns"
+	)
+
+	It("sees a throw-away cgroup as root", func() {
+	    cgroupnsfd, mntnsfd, cgroupPath := cgroupns.NewTransient()
+	    spacetest.Execute(func() {
+	        // ...
+	    }, spacetest.Fd(cgroupnsfd), spacetest.Fd(mntnsfd))
+	})
+
+Here, cgroupPath is the path of the throw-away cgroup in the cgroup2 hierarchy
+as seen from the test process, so that tests can, for instance, apply limits
+without the need to switch into the transient mount namespace.
+
+# Restrictions
+
+As cgroup v2 only allows individual threads of the same process to be in
+different cgroups in so-called threaded subtrees, the throw-away cgroup is
+turned into a “threaded” cgroup. In consequence, the cgroup of the test process
+becomes a “domain threaded” cgroup while throw-away cgroups exist, and reverts
+to a normal domain cgroup afterwards. This requires the cgroup of the test
+process to be able to serve as a threaded domain, which usually is the case when
+the test process is in the root cgroup, or in a leaf cgroup without further
+children. [NewTransient] refuses to create a throw-away cgroup when the cgroup of
+the test process has domain controllers enabled (such as “memory” or “io”) or
+has domain child cgroups, as is common with systemd slices; turning this cgroup
+into a threaded domain then would either fail or render its domain children
+invalid.
+*/
+package cgroupns
@@ -0,0 +1,42 @@
+// Copyright 2025 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cgroupns
+
+import (
+	"os"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("executing in other cgroup namespaces", Ordered, func() {
+
+	BeforeAll(func() {
+		if os.Getuid() != 0 {
+			Skip("needs root")
+		}
+	})
+
+	It("executes a func in a different cgroup namespace", func() {
+		cgroupns, _, _ := NewTransient()
+		count := 0
+		Execute(cgroupns, func() {
+			count++
+			Expect(CurrentIno()).To(Equal(Ino(cgroupns)))
+		})
+		Expect(count).To(Equal(1), "didn't call fn")
+	})
+
+})
@@ -0,0 +1,295 @@
+// Copyright 2025 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cgroupns
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/thediveo/spacetest"
+	"golang.org/x/sys/unix"
+
+	. "github.com/onsi/ginkgo/v2" //nolint:staticcheck // ST1001 rule does not apply
+	. "github.com/onsi/gomega"    //nolint:staticcheck // ST1001 rule does not apply
+)
+
+// NewTransient creates a new transient cgroup namespace that is rooted at a
+// new throw-away cgroup in the cgroup v2 hierarchy. It returns file descriptors
+// referencing the new cgroup namespace as well as a new transient mount
+// namespace with a fresh cgroup2 filesystem instance mounted in place of the
+// host's cgroup2 mount. Additionally, it returns the path of the throw-away
+// cgroup in the host's cgroup2 filesystem.
+//
+// The new namespaces are kept alive by an idle OS-level thread that has been
+// moved into the throw-away cgroup. At the end of the current test, this idle
+// thread is automatically terminated, the file descriptors closed, and the
+// throw-away cgroup removed.
+//
+// Please note that NewTransient changes the cgroup of the test process: as
+// the throw-away cgroup is a “threaded” cgroup, the cgroup of the test process
+// becomes a “domain threaded” cgroup for as long as throw-away cgroups exist.
+// NewTransient thus refuses to create a throw-away cgroup if the cgroup of the
+// test process cannot become a threaded domain without disturbing other
+// cgroups, that is, when it has domain controllers enabled or has domain child
+// cgroups.
+//
+// NewTransient is a thin wrapper around [NewTransientE], failing the current
+// test in case NewTransientE returns an error.
+func NewTransient() (cgroupnsfd int, mntnsfd int, cgroupPath string) {
+	GinkgoHelper()
+
+	cgroupnsfd, mntnsfd, cgroupPath, release, err := NewTransientE()
+	Expect(err).NotTo(HaveOccurred())
+	DeferCleanup(func() {
+		Expect(release()).To(Succeed())
+	})
+	return cgroupnsfd, mntnsfd, cgroupPath
+}
+
+// NewTransientE works like [NewTransient], but instead of failing the current
+// test it returns an error in case the transient cgroup namespace cannot be
+// created. NewTransientE thus can also be used outside Ginkgo tests.
+//
+// Instead of scheduling any cleanup, NewTransientE returns a release function
+// that the caller must call in order to terminate the idle OS-level thread,
+// close the returned file descriptors, and remove the throw-away cgroup.
+// Calling the release function more than once is safe.
+func NewTransientE() (cgroupnsfd int, mntnsfd int, cgroupPath string, release func() error, err error) {
+	cgroup2root, err := cgroup2Mountpoint()
+	if err != nil {
+		return -1, -1, "", nil, err
+	}
+	owncgroup, err := ownCgroup()
+	if err != nil {
+		return -1, -1, "", nil, err
+	}
+	parentPath := filepath.Join(cgroup2root, owncgroup)
+	if err := canBecomeThreadedDomain(parentPath); err != nil {
+		return -1, -1, "", nil, err
+	}
+	cgroupPath, err = os.MkdirTemp(parentPath, "spacetest-")
+	if err != nil {
+		return -1, -1, "", nil, fmt.Errorf("cannot create transient cgroup: %w", err)
+	}
+	// Only threads inside threaded subtrees can be in different cgroups than
+	// the other threads of the same process.
+	if err := os.WriteFile(filepath.Join(cgroupPath, "cgroup.type"), []byte("threaded"), 0); err != nil {
+		_ = unix.Rmdir(cgroupPath)
+		if errors.Is(err, unix.EOPNOTSUPP) || errors.Is(err, unix.EBUSY) {
+			return -1, -1, "", nil, fmt.Errorf(
+				"cannot make transient cgroup %s threaded, as cgroup %s cannot become a threaded domain: %w",
+				cgroupPath, parentPath, err)
+		}
+		return -1, -1, "", nil, fmt.Errorf("cannot make transient cgroup %s threaded: %w", cgroupPath, err)
+	}
+
+	// closing the done channel tells the idler go routine to terminate, which
+	// in turn closes the gone channel when it's about to go.
+	done := make(chan struct{})
+	gone := make(chan struct{})
+
+	readyCh := make(chan idlerDetails)
+	go func() {
+		defer close(gone)
+		runtime.LockOSThread() // never unlock, as this thread is going to be tainted
+
+		// Whatever is going to happen to us, make sure to unblock the receiving
+		// Go routine...
+		defer close(readyCh)
+
+		details := idle(cgroupPath, cgroup2root)
+		readyCh <- details
+		if details.err != nil {
+			return
+		}
+
+		<-done // ...idle around, then fall off the discworld...
+	}()
+	idlerInfo := <-readyCh
+	if idlerInfo.err != nil {
+		<-gone
+		_ = removeCgroup(cgroupPath)
+		return -1, -1, "", nil, idlerInfo.err
+	}
+	var once sync.Once
+	var releaseErr error
+	return idlerInfo.cgroupnsfd, idlerInfo.mntnsfd, cgroupPath, func() error {
+		once.Do(func() {
+			_ = unix.Close(idlerInfo.cgroupnsfd)
+			_ = unix.Close(idlerInfo.mntnsfd)
+			close(done)
+			<-gone
+			releaseErr = removeCgroup(cgroupPath)
+		})
+		return releaseErr
+	}, nil
+}
+
+// idlerDetails passes information about an idler's cgroup and mount namespace
+// references from the idler go routine to its creator.
+type idlerDetails struct {
+	cgroupnsfd int
+	mntnsfd    int
+	err        error
+}
+
+// idle moves the calling OS-level thread into the specified throw-away cgroup
+// and then attaches it to a new cgroup namespace as well as a new mount
+// namespace with a fresh cgroup2 filesystem instance mounted on cgroup2root.
+// It returns the details about the new namespaces.
+func idle(cgroupPath string, cgroup2root string) idlerDetails {
+	if err := os.WriteFile(filepath.Join(cgroupPath, "cgroup.threads"),
+		[]byte(strconv.Itoa(unix.Gettid())), 0); err != nil {
+		return idlerDetails{err: fmt.Errorf("cannot move idler thread into transient cgroup %s: %w",
+			cgroupPath, err)}
+	}
+	if err := unix.Unshare(unix.CLONE_NEWCGROUP); err != nil {
+		return idlerDetails{err: fmt.Errorf("cannot create new cgroup namespace: %w", err)}
+	}
+
+	// Decouple some filesystem-related attributes of this thread from the ones
+	// of our process, and create a new mount namespace in order to mount a new
+	// cgroup2 instance that sees our transient cgroup as its root.
+	if err := unix.Unshare(unix.CLONE_FS | unix.CLONE_NEWNS); err != nil {
+		return idlerDetails{err: fmt.Errorf("cannot create new mount namespace: %w", err)}
+	}
+	if err := unix.Mount("none", "/", "/", unix.MS_REC|unix.MS_PRIVATE, ""); err != nil {
+		return idlerDetails{err: fmt.Errorf("cannot change / mount propagation to private: %w", err)}
+	}
+	// The kernel refuses to mount the same cgroup2 superblock directly on top
+	// of itself, so we need to get rid of the inherited cgroup2 mount first.
+	if err := unix.Unmount(cgroup2root, unix.MNT_DETACH); err != nil {
+		return idlerDetails{err: fmt.Errorf("cannot unmount inherited cgroup2 instance from %s: %w",
+			cgroup2root, err)}
+	}
+	if err := unix.Mount("cgroup2", cgroup2root, "cgroup2",
+		unix.MS_NODEV|unix.MS_NOEXEC|unix.MS_NOSUID|unix.MS_RELATIME, ""); err != nil {
+		return idlerDetails{err: fmt.Errorf("cannot mount new cgroup2 instance on %s: %w",
+			cgroup2root, err)}
+	}
+
+	cgroupnsfd, err := unix.Open("/proc/thread-self/ns/cgroup", unix.O_RDONLY|unix.O_CLOEXEC, 0)
+	if err != nil {
+		return idlerDetails{err: fmt.Errorf("cannot determine new cgroup namespace from procfs: %w", err)}
+	}
+	mntnsfd, err := unix.Open("/proc/thread-self/ns/mnt", unix.O_RDONLY|unix.O_CLOEXEC, 0)
+	if err != nil {
+		_ = unix.Close(cgroupnsfd)
+		return idlerDetails{err: fmt.Errorf("cannot determine new mount namespace from procfs: %w", err)}
+	}
+	return idlerDetails{
+		cgroupnsfd: cgroupnsfd,
+		mntnsfd:    mntnsfd,
+	}
+}
+
+// removeCgroup removes the specified throw-away cgroup. As the idler thread
+// needs to first leave the transient cgroup before we can remove the cgroup,
+// we might need a few attempts.
+func removeCgroup(cgroupPath string) error {
+	var err error
+	for deadline := time.Now().Add(2 * time.Second); time.Now().Before(deadline); {
+		if err = unix.Rmdir(cgroupPath); err == nil || errors.Is(err, unix.ENOENT) {
+			return nil
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	return fmt.Errorf("cannot remove transient cgroup %s: %w", cgroupPath, err)
+}
+
+// canBecomeThreadedDomain returns an error if the specified cgroup cannot
+// become a threaded domain without disturbing other cgroups: the kernel
+// refuses threaded children of cgroups with domain controllers enabled, and it
+// renders existing domain children of a threaded domain invalid. The root
+// cgroup can always have threaded children.
+func canBecomeThreadedDomain(cgroupPath string) error {
+	if _, err := os.Stat(filepath.Join(cgroupPath, "cgroup.type")); errors.Is(err, os.ErrNotExist) {
+		return nil // ...root cgroup
+	}
+	controllers, err := os.ReadFile(filepath.Join(cgroupPath, "cgroup.subtree_control"))
+	if err != nil {
+		return fmt.Errorf("cannot determine enabled controllers of cgroup %s: %w", cgroupPath, err)
+	}
+	for _, controller := range strings.Fields(string(controllers)) {
+		if !slices.Contains(threadedControllers, controller) {
+			return fmt.Errorf("cgroup %s cannot become a threaded domain, as it has the domain controller %q enabled",
+				cgroupPath, controller)
+		}
+	}
+	entries, err := os.ReadDir(cgroupPath)
+	if err != nil {
+		return fmt.Errorf("cannot determine child cgroups of cgroup %s: %w", cgroupPath, err)
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		typ, err := os.ReadFile(filepath.Join(cgroupPath, entry.Name(), "cgroup.type"))
+		if err != nil {
+			return fmt.Errorf("cannot determine type of child cgroup %s: %w", entry.Name(), err)
+		}
+		if strings.HasPrefix(string(typ), "domain") {
+			return fmt.Errorf("cgroup %s cannot become a threaded domain, as it has the domain child cgroup %s",
+				cgroupPath, entry.Name())
+		}
+	}
+	return nil
+}
+
+// threadedControllers lists the cgroup v2 controllers that support threaded
+// mode, see also [cgroups(7)].
+//
+// [cgroups(7)]: https://man7.org/linux/man-pages/man7/cgroups.7.html
+var threadedControllers = []string{"cpu", "cpuset", "perf_event", "pids"}
+
+// cgroup2Mountpoint returns the mount point of the cgroup2 filesystem in the
+// caller's mount namespace, or an error if there is no cgroup2 filesystem
+// mounted.
+func cgroup2Mountpoint() (string, error) {
+	mounts, err := spacetest.ReadMountInfoE("/proc/thread-self/mountinfo")
+	if err != nil {
+		return "", err
+	}
+	for _, mount := range mounts {
+		if mount.FSType == "cgroup2" && mount.Root == "/" {
+			return mount.MountPoint, nil
+		}
+	}
+	return "", errors.New("no cgroup2 filesystem mounted")
+}
+
+// ownCgroup returns the path of the cgroup v2 the calling process is a member
+// of.
+func ownCgroup() (string, error) {
+	cgroups, err := os.ReadFile("/proc/self/cgroup")
+	if err != nil {
+		return "", fmt.Errorf("cannot determine own cgroup: %w", err)
+	}
+	for line := range strings.Lines(string(cgroups)) {
+		path, ok := strings.CutPrefix(line, "0::")
+		if ok {
+			return strings.TrimSuffix(path, "\n"), nil
+		}
+	}
+	return "", errors.New("not a member of any cgroup v2")
+}
@@ -0,0 +1,90 @@
+// Copyright 2025 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cgroupns
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/thediveo/spacetest"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gleak"
+	. "github.com/thediveo/fdooze"
+	. "github.com/thediveo/success"
+)
+
+var _ = Describe("transient cgroup namespaces", func() {
+
+	BeforeEach(func() {
+		if os.Getuid() != 0 {
+			Skip("needs root")
+		}
+		goodfds := Filedescriptors()
+		goodgos := Goroutines()
+		DeferCleanup(func() {
+			Eventually(Goroutines).Within(2 * time.Second).ProbeEvery(250 * time.Millisecond).
+				ShouldNot(HaveLeaked(goodgos))
+			Expect(Filedescriptors()).NotTo(HaveLeakedFds(goodfds))
+		})
+	})
+
+	It("creates a new cgroup namespace rooted at a throw-away cgroup", func() {
+		var cgroupPath string
+		// Schedule our check before NewTransient schedules its cleanup, so
+		// that our check runs after the throw-away cgroup should be gone.
+		DeferCleanup(func() {
+			Expect(cgroupPath).NotTo(BeADirectory())
+		})
+
+		var cgroupnsfd, mntnsfd int
+		cgroupnsfd, mntnsfd, cgroupPath = NewTransient()
+		Expect(Ino(cgroupnsfd)).NotTo(Equal(CurrentIno()))
+		Expect(cgroupPath).To(BeADirectory())
+		threads := Successful(os.ReadFile(filepath.Join(cgroupPath, "cgroup.threads")))
+		Expect(strings.TrimSpace(string(threads))).To(
+			MatchRegexp(`^\d+$`), "expected exactly one thread in the throw-away cgroup")
+
+		cgroup2root := Successful(cgroup2Mountpoint())
+		spacetest.Execute(func() {
+			// Only non-root cgroups have a cgroup.type, so we can be sure
+			// that we're not seeing the host's cgroup root.
+			Expect(strings.TrimSpace(string(Successful(
+				os.ReadFile(filepath.Join(cgroup2root, "cgroup.type")))))).To(
+				Equal("threaded"))
+			Expect(os.ReadFile(filepath.Join(cgroup2root, "cgroup.threads"))).To(
+				Equal(threads))
+		}, spacetest.Fd(cgroupnsfd), spacetest.Fd(mntnsfd))
+	})
+
+	It("refuses to turn a cgroup with domain children into a threaded domain", func() {
+		cgroupPath := filepath.Join(Successful(cgroup2Mountpoint()), Successful(ownCgroup()))
+		Expect(canBecomeThreadedDomain(cgroupPath)).To(Succeed())
+
+		parent := Successful(os.MkdirTemp(cgroupPath, "spacetest-"))
+		DeferCleanup(func() { Expect(os.Remove(parent)).To(Succeed()) })
+		Expect(canBecomeThreadedDomain(parent)).To(Succeed())
+
+		child := filepath.Join(parent, "domain")
+		Expect(os.Mkdir(child, 0o755)).To(Succeed())
+		DeferCleanup(func() { Expect(os.Remove(child)).To(Succeed()) })
+		Expect(canBecomeThreadedDomain(parent)).To(MatchError(
+			ContainSubstring("has the domain child cgroup domain")))
+	})
+
+})
@@ -0,0 +1,142 @@
+// Copyright 2025 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spacetest
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/onsi/ginkgo/v2/types"
+
+	. "github.com/onsi/ginkgo/v2" //nolint:staticcheck // ST1001 rule does not apply
+	. "github.com/onsi/gomega"    //nolint:staticcheck // ST1001 rule does not apply
+)
+
+// ContaminatedThread describes an OS-level thread of the process that is
+// attached to namespaces different from the baseline namespaces of the
+// process.
+type ContaminatedThread struct {
+	TID        int      // thread ID.
+	Namespaces []string // differing namespaces, such as “net:[4026532xxx] instead of net:[4026531840]”.
+}
+
+// String returns a textual description of the contaminated thread and its
+// differing namespaces.
+func (c ContaminatedThread) String() string {
+	return fmt.Sprintf("thread %d attached to %s", c.TID, strings.Join(c.Namespaces, ", "))
+}
+
+// ContaminatedThreads returns the OS-level threads of the process that are
+// attached to namespaces different from the baseline namespaces of the
+// process. The baseline is formed by the namespaces of the process's initial
+// thread, which the spacetest package keeps locked so that it never gets
+// switched into other namespaces.
+//
+// Please note that threads kept locked on purpose, such as the idle threads
+// keeping transient mount namespaces alive, are reported as contaminated as
+// long as they exist.
+func ContaminatedThreads() []ContaminatedThread {
+	baseline := threadNamespaces(os.Getpid())
+	var contaminated []ContaminatedThread
+	taskentries, _ := os.ReadDir("/proc/self/task")
+	for _, taskentry := range taskentries {
+		tid, err := strconv.Atoi(taskentry.Name())
+		if err != nil || tid == os.Getpid() {
+			continue
+		}
+		var differing []string
+		for name, link := range threadNamespaces(tid) {
+			if baselink, ok := baseline[name]; ok && link != baselink {
+				differing = append(differing, link+" instead of "+baselink)
+			}
+		}
+		if len(differing) == 0 {
+			continue
+		}
+		slices.Sort(differing)
+		contaminated = append(contaminated, ContaminatedThread{
+			TID:        tid,
+			Namespaces: differing,
+		})
+	}
+	return contaminated
+}
+
+// threadNamespaces returns the textual namespace representations of the
+// namespaces the specified thread of the process is attached to, indexed by
+// their /proc/$PID/task/$TID/ns entry names, such as “net” and
+// “pid_for_children”. If the thread has vanished in the meantime,
+// threadNamespaces returns an empty map.
+func threadNamespaces(tid int) map[string]string {
+	namespaces := map[string]string{}
+	nsdir := filepath.Join("/proc/self/task", strconv.Itoa(tid), "ns")
+	nsentries, _ := os.ReadDir(nsdir)
+	for _, nsentry := range nsentries {
+		link, err := os.Readlink(filepath.Join(nsdir, nsentry.Name()))
+		if err != nil {
+			continue
+		}
+		namespaces[nsentry.Name()] = link
+	}
+	return namespaces
+}
+
+// ExpectUncontaminatedThreads fails the current test if any of the process's
+// OS-level threads remains attached to namespaces different from the baseline
+// namespaces of the process for more than the specified duration (defaulting
+// to Gomega's default Eventually timeout), naming the offending threads and
+// their namespaces. It is intended to be called from an AfterSuite node:
+//
+//	var _ = AfterSuite(func() {
+//	    spacetest.ExpectUncontaminatedThreads()
+//	})
+//
+// As tainted threads of finished go routines are only thrown away
+// asynchronously, ExpectUncontaminatedThreads waits for them to vanish.
+func ExpectUncontaminatedThreads(within ...time.Duration) {
+	GinkgoHelper()
+
+	e := Eventually(ContaminatedThreads)
+	if len(within) > 0 {
+		e = e.Within(within[0])
+	}
+	e.ProbeEvery(10*time.Millisecond).Should(BeEmpty(),
+		"OS-level threads attached to non-baseline namespaces")
+}
+
+// AuditThreadNamespaces registers a ReportAfterEach node that fails each spec
+// that leaves behind OS-level threads attached to namespaces different from
+// the baseline namespaces of the process; please see
+// [ExpectUncontaminatedThreads] for details. Such contaminated threads
+// otherwise would be silently reused by other go routines, such as later
+// specs.
+//
+// AuditThreadNamespaces must be called at the top level of a test suite, for
+// instance:
+//
+//	var _ = spacetest.AuditThreadNamespaces()
+func AuditThreadNamespaces(within ...time.Duration) bool {
+	return ReportAfterEach(func(report SpecReport) {
+		if report.State.Is(types.SpecStateSkipped | types.SpecStatePending) {
+			return
+		}
+		ExpectUncontaminatedThreads(within...)
+	})
+}
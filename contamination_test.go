@@ -0,0 +1,60 @@
+// Copyright 2025 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spacetest
+
+import (
+	"runtime"
+	"time"
+
+	"golang.org/x/sys/unix"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("thread namespace contamination", func() {
+
+	It("doesn't report uncontaminated threads", func() {
+		Expect(ContaminatedThreads()).To(BeEmpty())
+	})
+
+	It("reports contaminated threads", func() {
+		done := make(chan struct{})
+		ready := make(chan int)
+		go func() {
+			defer GinkgoRecover()
+			runtime.LockOSThread() // never unlock, as this thread is going to be tainted
+
+			Expect(unix.Unshare(unix.CLONE_NEWUTS)).To(Succeed())
+			ready <- unix.Gettid()
+			<-done
+		}()
+		tid := <-ready
+
+		Expect(ContaminatedThreads()).To(ConsistOf(And(
+			HaveField("TID", tid),
+			HaveField("Namespaces", ConsistOf(MatchRegexp(`^uts:\[\d+\] instead of uts:\[\d+\]$`))),
+			WithTransform(ContaminatedThread.String,
+				MatchRegexp(`^thread %d attached to uts:\[\d+\] instead of uts:\[\d+\]$`, tid)),
+		)))
+		Expect(InterceptGomegaFailure(func() {
+			ExpectUncontaminatedThreads(50 * time.Millisecond)
+		})).To(MatchError(ContainSubstring("OS-level threads attached to non-baseline namespaces")))
+
+		close(done)
+		ExpectUncontaminatedThreads()
+	})
+
+})
@@ -8,11 +8,116 @@ namespace handled, but different test helper functions come with different
 restrictions due to restrictions imposed by the Linux kernel. Please carefully
 check the documentation for the individual helper functions.
 
+# Namespace Handles
+
+Instead of bare int file descriptors, namespaces can also be referenced using
+[Namespace] handles that know their type of namespace, for instance, rendering
+as “net:[4026531840]” in test failure messages. Helpers such as [Type] and
+[Ino] accept both file descriptors and Namespace handles, and
+[CurrentNamespace] and [NewTransientNamespace] return Namespace handles. The
+file descriptor-based [Current] and [NewTransient] remain as thin wrappers.
+[Execute] and friends take [NamespaceRef]s, so that Namespace handles and file
+descriptors wrapped in [Fd] can be mixed in the same call.
+
+# Namespace Sets
+
+[NewTransientSet] and [EnterTransientSet] create (and enter) multiple new
+namespaces of OR'ed types, such as unix.CLONE_NEWNET|unix.CLONE_NEWUTS, in a
+single step. Sets including a new mount namespace automatically keep it alive
+using an idle OS-level thread, same as the spacetest/mntns package does. Use
+[WithMountSetup] or mntns.ForTransientSet to set up such a new mount namespace
+differently than remounting “/” with private mount point propagation.
+
+# Namespace Identities
+
+As inode numbers alone don't strictly identify namespaces, [ID] combines the
+device and inode numbers with the 64-bit namespace ID on kernels supporting
+it; see [IDOf] and [CurrentID]. [EncodeFileHandle] references a namespace by an
+nsfs file handle without keeping the namespace alive, to be reopened later
+using [FileHandle.Open]. On older kernels, FileHandle falls back to keeping a
+file descriptor open instead.
+
+# Namespaces of Other Processes
+
+[FromPID] and [FromPIDFd] return Namespace handles for the namespaces of other
+processes, such as child processes or containers, referenced either by PID or
+pidfd. Where supported by the kernel, the PIDFD_GET_*_NAMESPACE ioctls are used,
+falling back to procfs otherwise. In order to run a function attached to
+several namespaces of another process, pass these Namespace handles to
+[Execute].
+
+# Namespace Relations
+
+[Owner] and [Parent] return the owning user namespace and the parent namespace,
+respectively, of a referenced namespace, and [OwnerUID] the UID of the creator
+of a user namespace. [Ancestors] walks the chain of parent namespaces up to the
+initial namespace, or as far as the caller is allowed to see.
+
+# Matchers
+
+The Gomega matchers [BeNamespaceOfType], [BeSameNamespaceAs],
+[BeCurrentNamespace], [BeOwnedByUserNamespace], and [BeChildNamespaceOf] accept
+namespaces referenced by file descriptors, VFS path names, open files, or
+Namespace handles. In case of failure, they describe the namespaces involved in
+the form of “net:[4026531840]” instead of bare inode numbers.
+
+# Pinning Namespaces
+
+[Pin] bind-mounts a namespace onto a file, so that the namespace can be
+inspected using tools such as “nsenter” while a test runs. The pins get
+automatically removed at the end of the test, unless [KeepPinsOnFailure] has
+been enabled and the test failed, allowing for post-mortem inspection.
+
+# Asynchronous Execution
+
+[Go] runs a function asynchronously on a dedicated OS-level thread attached to
+the specified namespaces, returning an [Execution] handle to wait for the
+function to finish. When the passed context expires while the function is
+still running, the Execution reports this and its tainted thread will be thrown
+away instead of getting reused.
+
+# Workers
+
+A [Worker] keeps a locked OS-level thread permanently attached to a fixed set
+of namespaces, executing functions submitted by [Worker.Execute]. This avoids
+switching namespaces in and out when running many small probes inside the same
+namespaces.
+
+# Error-Returning Core
+
+The test helpers fail the current Ginkgo test and schedule Ginkgo cleanups.
+For plain Go tests, TestMain, or small tools, [ExecuteE], [NewTransientE],
+[NewTransientNamespaceE], and [EnterTransientE] instead return errors and leave
+closing the namespaces created to their callers. The Ginkgo-oriented helpers
+are thin wrappers around them.
+
+For tests based on the standard testing package instead of Ginkgo, the
+spacetest/testingns package and its subpackages offer adapters that register
+their cleanups using t.Cleanup and report failures using t.Fatalf.
+
+# Namespace Leaks
+
+[HeldNamespaces] takes a snapshot of all namespaces the process keeps alive
+through open file descriptors, its threads, and bind mounts. Comparing
+snapshots taken before and after a test using [HaveLeakedNamespaces] then
+reports leaked namespaces together with what is holding them.
+
+# Thread Contamination
+
+[ContaminatedThreads] reports any OS-level threads of the process that are
+attached to namespaces different from the baseline namespaces of the process.
+[AuditThreadNamespaces] registers a ReportAfterEach node that fails specs
+leaving such contaminated threads behind, as they otherwise would be silently
+reused by other go routines. In AfterSuite nodes, use
+[ExpectUncontaminatedThreads] instead.
+
 # Network Namespaces
 
 The spacetest/netns package is basically just a convenience wrapper around the
 generic test helper functions from the base spacetest package. Yet, this package
-helps DRY, especially avoiding litanies of [unix.CLONE_NEWNET].
+helps DRY, especially avoiding litanies of [unix.CLONE_NEWNET]. Additionally,
+it creates named network namespaces pinned in “/run/netns”, so that they show
+up in “ip netns list”.
 
 # Mount Namespaces
 
@@ -24,12 +129,42 @@ mount point propagation to avoid mishaps (trust us, we /know/).
 Other than that, this package helps again with DRY, such as [unix.CLONE_NEWNS]
 litanies.
 
+# UTS Namespaces
+
+The spacetest/utsns package wraps the generic helpers for [unix.CLONE_NEWUTS]
+and additionally allows setting host and domain names in transient UTS
+namespaces, refusing to touch the names of the host.
+
+# IPC Namespaces
+
+The spacetest/ipcns package wraps the generic helpers for [unix.CLONE_NEWIPC]
+and additionally provides fixtures for System V IPC objects and POSIX message
+queues that get automatically removed at the end of a test.
+
+# Cgroup Namespaces
+
+The spacetest/cgroupns package creates transient cgroup namespaces that are
+rooted at their own throw-away cgroup v2 subtree, instead of the caller's
+current cgroup.
+
+# Time Namespaces
+
+The spacetest/timens package creates transient time namespaces with offsets for
+the monotonic and boot time clocks, and then runs child processes inside them.
+
 # PID and User Namespaces
 
 Please note that user and PID namespaces are notoriously difficult to work with,
 especially in multi-threaded Go tests. Thus, the spacetest package has somewhat
 limited support for dealing with user namespaces. Please refer to the
-[github.com/thediveo/spacetest/spacer] package for details.
+[github.com/thediveo/spacetest/spacer] package for details, which also allows
+running registered functions in child processes attached to user namespaces.
+
+Nevertheless, [NewTransient] supports creating PID namespaces, initially
+without any processes. [StartPID1] then starts a command as PID 1 of such a new
+PID namespace, with a private /proc in a transient mount namespace. Creating
+PID namespaces needs Linux 6.11+ and StartPID1 needs Linux 6.15+; on older
+kernels, the E variants return errors wrapping [errors.ErrUnsupported].
 
 # Background
 
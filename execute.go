@@ -15,6 +15,7 @@
 package spacetest
 
 import (
+	"errors"
 	"fmt"
 	"runtime"
 	"slices"
@@ -43,36 +44,116 @@ import (
 // When the list of namespaces to switch to does not contain a mount namespace
 // then the passed fn will be called synchronously on the caller's go routine,
 // while locked to the underlying OS-level thread.
-func Execute(fn func(), nsfd int, nsfds ...int) {
+//
+// The namespaces are passed as [NamespaceRef]s, that is, either as [Namespace]
+// handles or as open file descriptors wrapped in [Fd], also mixed in the same
+// call.
+//
+// Execute is a thin wrapper around [ExecuteE], failing the current test in case
+// ExecuteE returns an error.
+func Execute(fn func(), nsref NamespaceRef, nsrefs ...NamespaceRef) {
+	GinkgoHelper()
+
+	Expect(ExecuteE(fn, nsref, nsrefs...)).To(Succeed())
+}
+
+// ExecuteValue works like [Execute], but returns the value returned by the
+// passed fn. This allows, for instance, reading a sysctl from another
+// namespace in a single expression.
+//
+// Same as Execute, ExecuteValue fails the current test if switching
+// namespaces fails and passes panics raised by fn on to the caller.
+func ExecuteValue[T any](fn func() T, nsref NamespaceRef, nsrefs ...NamespaceRef) T {
+	GinkgoHelper()
+
+	var value T
+	Execute(func() { value = fn() }, nsref, nsrefs...)
+	return value
+}
+
+// ExecuteErr works like [Execute], but returns the error returned by the passed
+// fn.
+//
+// Same as Execute, ExecuteErr fails the current test if switching namespaces
+// fails and passes panics raised by fn on to the caller. Use [ExecuteE] in
+// order to instead receive namespace switching errors.
+func ExecuteErr(fn func() error, nsref NamespaceRef, nsrefs ...NamespaceRef) error {
 	GinkgoHelper()
 
-	var mntnsfd = int(-1)
-	var othernsfds []int
+	var err error
+	Execute(func() { err = fn() }, nsref, nsrefs...)
+	return err
+}
+
+// ExecuteE works like [Execute], but instead of failing the current test it
+// returns an error in case switching into the specified namespaces or back
+// fails. ExecuteE thus can also be used outside Ginkgo tests.
+//
+// Panics raised by fn are passed on to the caller of ExecuteE.
+//
+// If ExecuteE cannot restore the caller's original namespaces after fn
+// returned, it returns an error and leaves the caller's go routine locked to
+// its now tainted OS-level thread, so that this thread gets thrown away when
+// the go routine finishes.
+func ExecuteE(fn func(), nsref NamespaceRef, nsrefs ...NamespaceRef) error {
+	mntnsfd, othernsfds, err := sortOut(append([]NamespaceRef{nsref}, nsrefs...))
+	if errors.Is(err, errUserNamespace) {
+		return fmt.Errorf("cannot Execute() %w", err)
+	} else if err != nil {
+		return err
+	}
+
+	if mntnsfd >= 0 {
+		return goSeparate(fn, mntnsfd, othernsfds...)
+	}
+	return goInAndOut(fn, othernsfds...)
+}
 
-	for _, nsfd := range append([]int{nsfd}, nsfds...) {
-		switch Type(nsfd) {
+// errUserNamespace signals an attempt to switch into a different user
+// namespace.
+var errUserNamespace = errors.New("in different user namespace")
+
+// sortOut returns the file descriptor of the mount namespace (or -1 if none)
+// as well as the file descriptors of the other namespaces in the passed list of
+// namespace references. sortOut returns an error if any of the references
+// doesn't reference a namespace (of the required type, if any) or references a
+// user namespace; in the latter case, the error returned is errUserNamespace.
+func sortOut(nsrefs []NamespaceRef) (mntnsfd int, othernsfds []int, err error) {
+	mntnsfd = -1
+	for _, nsref := range nsrefs {
+		if nsref == nil {
+			return -1, nil, errors.New("expected a namespace reference, got nil")
+		}
+		nsfd := nsref.Fd()
+		if nsfd < 0 {
+			return -1, nil, fmt.Errorf("invalid namespace reference %v", nsref)
+		}
+		typ, err := typeOfFd(nsfd)
+		if err != nil {
+			return -1, nil, err
+		}
+		if typed, ok := nsref.(typedRef); ok && typ != typed.typ {
+			return -1, nil, fmt.Errorf("not a %s namespace, but %s", Name(typed.typ), Name(typ))
+		}
+		switch typ {
 		case unix.CLONE_NEWUSER:
-			Expect("user").NotTo(Equal("user"), "cannot Execute() in different user namespace")
+			return -1, nil, errUserNamespace
 		case unix.CLONE_NEWNS:
 			mntnsfd = nsfd
 		default:
 			othernsfds = append(othernsfds, nsfd)
 		}
 	}
-
-	if mntnsfd >= 0 {
-		goSeparate(fn, mntnsfd, othernsfds...)
-		return
-	}
-	goInAndOut(fn, othernsfds...)
+	return mntnsfd, othernsfds, nil
 }
 
 // goInAndOut runs the passed fn on the current go routine and locked to its
 // OS-level thread, temporarily switching into the specified namespaces while fn
 // runs.
 //
-// If anything fails, this will automatically fail the current test.
-func goInAndOut(fn func(), othernsfds ...int) {
+// If anything fails, goInAndOut returns an error and doesn't unlock the
+// OS-level thread.
+func goInAndOut(fn func(), othernsfds ...int) (err error) {
 	runtime.LockOSThread()
 
 	var callersNamespaces []int
@@ -83,22 +164,33 @@ func goInAndOut(fn func(), othernsfds ...int) {
 	}()
 	defer func() {
 		// In case we came here because switching into the specified namespaces
-		// failed, then we silently try to restore things as good as possible
-		// (which is questionable) and then re-panic. We never unlock the
-		// OS-level thread from its go routine.
+		// failed or fn panicked, then we silently try to restore things as
+		// good as possible (which is questionable) and then re-panic or pass
+		// on the error. We never unlock the OS-level thread from its go
+		// routine in these cases.
 		if r := recover(); r != nil {
 			for _, nsfd := range slices.Backward(callersNamespaces) {
 				_ = unix.Setns(nsfd, 0)
 			}
 			panic(r)
 		}
+		if err != nil {
+			for _, nsfd := range slices.Backward(callersNamespaces) {
+				_ = unix.Setns(nsfd, 0)
+			}
+			return
+		}
 		// Try restoring the namespaces that the caller was attached to before
 		// we temporarily switched into different ones.
 		for _, nsfd := range slices.Backward(callersNamespaces) {
-			Expect(unix.Setns(nsfd, 0)).To(Succeed(),
-				func() string {
-					return fmt.Sprintf("cannot restore %s namespace", Name(Type(nsfd)))
-				})
+			if seterr := unix.Setns(nsfd, 0); seterr != nil {
+				typename := "unknown"
+				if typ, err := typeOfFd(nsfd); err == nil {
+					typename = Name(typ)
+				}
+				err = fmt.Errorf("cannot restore %s namespace: %w", typename, seterr)
+				return
+			}
 		}
 		// Only unlock OS-level thread from go routine if we were successful in
 		// restoring all changed namespaces.
@@ -107,19 +199,24 @@ func goInAndOut(fn func(), othernsfds ...int) {
 
 	// Attach to the specified namespaces and fail if this doesn't work.
 	for _, nsfd := range othernsfds {
-		typ := Type(nsfd)
+		typ, err := typeOfFd(nsfd)
+		if err != nil {
+			return err
+		}
 		typename := Name(typ)
-		currentnsfd, err := unix.Open("/proc/thread-self/ns/"+typename, unix.O_RDONLY, 0)
-		Expect(err).NotTo(HaveOccurred(),
-			"cannot determine current %s namespace from procfs", typename)
+		currentnsfd, err := unix.Open("/proc/thread-self/ns/"+typename, unix.O_RDONLY|unix.O_CLOEXEC, 0)
+		if err != nil {
+			return fmt.Errorf("cannot determine current %s namespace from procfs: %w",
+				typename, err)
+		}
 		callersNamespaces = append(callersNamespaces, currentnsfd)
-		Expect(unix.Setns(nsfd, typ)).To(Succeed(),
-			func() string {
-				return fmt.Sprintf("cannot switch into %s namespace", Name(typ))
-			})
+		if err := unix.Setns(nsfd, typ); err != nil {
+			return fmt.Errorf("cannot switch into %s namespace: %w", typename, err)
+		}
 	}
 
 	fn()
+	return nil
 }
 
 // goSeparate runs the passed fn on a separate go routine, locked to its
@@ -133,74 +230,51 @@ func goInAndOut(fn func(), othernsfds ...int) {
 // been explicitly overrriden/passed in othernsfds. This ensures that fn is
 // executed in the same namespace configuration as the caller.
 //
-// If anything fails, goSeparate will automatically fail the current test.
-func goSeparate(fn func(), mntnsfd int, othernsfds ...int) {
+// If anything fails, goSeparate returns an error.
+func goSeparate(fn func(), mntnsfd int, othernsfds ...int) error {
 	runtime.LockOSThread()
 	defer runtime.UnlockOSThread()
 
-	// Find out which of the non-user and non-mount namespaces aren't explicitly
-	// set and which we therefore need to take over from the caller; the
-	// caller's OS-level thread might have partically differing namespaces
-	// configured compared to a fresh or reused "untainted" OS-level thread.
-	pickupTypes := []int{
-		unix.CLONE_NEWCGROUP,
-		unix.CLONE_NEWIPC,
-		unix.CLONE_NEWNET,
-		unix.CLONE_NEWPID,
-		unix.CLONE_NEWTIME,
-		unix.CLONE_NEWUTS,
-	}
-	for _, nsfd := range othernsfds {
-		typ := Type(nsfd)
-		pickupTypes = slices.DeleteFunc(pickupTypes, func(e int) bool { return e == typ })
-	}
-	var pickupfds []int
-	for _, typ := range pickupTypes {
-		pickupfds = append(pickupfds, Current(typ))
+	pickupfds, err := pickup(othernsfds)
+	// Always properly close the namespace reference fds that we had opened in
+	// order to ensure that the new transient thread is attached to the same
+	// namespaces the caller is attached to, except for those explicitly
+	// overridden.
+	defer closeAll(pickupfds)
+	if err != nil {
+		return err
 	}
 
-	panicCh := make(chan any)
+	return goThrowaway(fn, func() error {
+		return attach(mntnsfd, append(othernsfds, pickupfds...))
+	})
+}
+
+// goThrowaway runs the passed fn on a separate go routine that is locked to its
+// OS-level thread and never unlocked, so that this thread gets thrown away
+// afterwards. Before calling fn, goThrowaway calls the passed attach function
+// on the separate go routine in order to attach its thread to the required
+// namespaces. Panics raised by fn are rethrown on the caller's go routine.
+func goThrowaway(fn func(), attach func() error) error {
+	type outcome struct {
+		err      error
+		panicked any
+	}
+	outcomeCh := make(chan outcome)
 	go func() {
 		// We cannot really do a sensible "defer GinkgoRecover()" here, so
 		// instead we're catching any panics and rethrow them on the caller's go
 		// routine.
+		var err error
 		defer func() {
-			// Always properly close the namespace reference fds that we had
-			// opened in order to ensure that the new transient thread is
-			// attached to the same namespaces the caller is attached to, except
-			// for those explicitly overridden.
-			for _, nsfd := range pickupfds {
-				_ = unix.Close(nsfd)
-			}
-			if r := recover(); r != nil {
-				panicCh <- r
-			}
-			close(panicCh)
+			outcomeCh <- outcome{err: err, panicked: recover()}
 		}()
 
 		runtime.LockOSThread()
 
-		if mntnsfd >= 0 {
-			Expect(unix.Unshare(unix.CLONE_FS)).To(Succeed(),
-				"cannot unshare file attributes of transient func call OS-level thread")
-			Expect(unix.Setns(mntnsfd, unix.CLONE_NEWNS)).To(Succeed(),
-				"cannot switch into mnt namespace")
-		}
-
-		// now attach our separate thread to the same namespaces as the caller's
-		// thread was attached to, except where the caller told us different
-		// namespaces.
-		for _, nsfd := range append(othernsfds, pickupfds...) {
-			typ := Type(nsfd)
-			name := Name(typ)
-			if Ino(nsfd, typ) == Ino("/proc/thread-self/ns/"+name, typ) {
-				// skip unnecessary namespace switching from the one namespace
-				// into the same, as these may fail and thus cause us otherwise
-				// unwanted false positives.
-				continue
-			}
-			Expect(unix.Setns(nsfd, 0)).To(Succeed(),
-				"cannot switch into %s namespace", name)
+		err = attach()
+		if err != nil {
+			return
 		}
 
 		// setup is finally complete, call the passed fn and then let's be done
@@ -209,7 +283,102 @@ func goSeparate(fn func(), mntnsfd int, othernsfds ...int) {
 	}()
 
 	// receive panic, if any, and rethrow it on the caller's go routine.
-	if r := <-panicCh; r != nil {
-		panic(r)
+	o := <-outcomeCh
+	if o.panicked != nil {
+		panic(o.panicked)
+	}
+	return o.err
+}
+
+// pickup returns file descriptors referencing those namespaces of the caller's
+// OS-level thread that aren't overridden by the passed namespace references
+// (and aren't user or mount namespaces). In case of an error, the file
+// descriptors already opened are returned too, so the caller must always close
+// them.
+func pickup(othernsfds []int) ([]int, error) {
+	overridden := 0
+	for _, nsfd := range othernsfds {
+		typ, err := typeOfFd(nsfd)
+		if err != nil {
+			return nil, err
+		}
+		overridden |= typ
+	}
+	// Find out which of the non-user and non-mount namespaces aren't explicitly
+	// set and which we therefore need to take over from the caller; the
+	// caller's OS-level thread might have partically differing namespaces
+	// configured compared to a fresh or reused "untainted" OS-level thread.
+	var pickupfds []int
+	for _, typ := range []int{
+		unix.CLONE_NEWCGROUP,
+		unix.CLONE_NEWIPC,
+		unix.CLONE_NEWNET,
+		unix.CLONE_NEWPID,
+		unix.CLONE_NEWTIME,
+		unix.CLONE_NEWUTS,
+	} {
+		if typ&overridden != 0 {
+			continue
+		}
+		typename := Name(typ)
+		nsfd, err := unix.Open("/proc/thread-self/ns/"+typename, unix.O_RDONLY|unix.O_CLOEXEC, 0)
+		if err != nil {
+			return pickupfds, fmt.Errorf("cannot determine current %s namespace from procfs: %w",
+				typename, err)
+		}
+		pickupfds = append(pickupfds, nsfd)
+	}
+	return pickupfds, nil
+}
+
+// closeAll closes the passed file descriptors, ignoring any errors.
+func closeAll(fds []int) {
+	for _, fd := range fds {
+		_ = unix.Close(fd)
+	}
+}
+
+// attach the calling OS-level thread to the specified mount namespace (if
+// mntnsfd >= 0) and other namespaces. Attaching to a mount namespace
+// additionally unshares the thread's filesystem attributes, so the calling OS
+// thread must be thrown away afterwards.
+func attach(mntnsfd int, nsfds []int) error {
+	if mntnsfd >= 0 {
+		if err := unix.Unshare(unix.CLONE_FS); err != nil {
+			return fmt.Errorf("cannot unshare file attributes of transient func call OS-level thread: %w",
+				err)
+		}
+		if err := unix.Setns(mntnsfd, unix.CLONE_NEWNS); err != nil {
+			return fmt.Errorf("cannot switch into mnt namespace: %w", err)
+		}
+	}
+
+	// now attach our separate thread to the same namespaces as the caller's
+	// thread was attached to, except where the caller told us different
+	// namespaces.
+	for _, nsfd := range nsfds {
+		typ, err := typeOfFd(nsfd)
+		if err != nil {
+			return err
+		}
+		id, err := idOfFd(nsfd)
+		if err != nil {
+			return err
+		}
+		name := Name(typ)
+		current, err := IDOfE("/proc/thread-self/ns/" + name)
+		if err != nil {
+			return err
+		}
+		if id.Equal(current) {
+			// skip unnecessary namespace switching from the one namespace
+			// into the same, as these may fail and thus cause us otherwise
+			// unwanted false positives.
+			continue
+		}
+		if err := unix.Setns(nsfd, 0); err != nil {
+			return fmt.Errorf("cannot switch into %s namespace: %w", name, err)
+		}
 	}
+	return nil
 }
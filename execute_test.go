@@ -66,13 +66,13 @@ var _ = Describe("doing things in different namespaces", Ordered, func() {
 			utsns := NewTransient(unix.CLONE_NEWUTS)
 
 			count := 0
-			goInAndOut(func() {
+			Expect(goInAndOut(func() {
 				count++
 				Expect(Ino("/proc/thread-self/ns/net", unix.CLONE_NEWNET)).To(
 					Equal(Ino(netns, unix.CLONE_NEWNET)), "net switch failed")
 				Expect(Ino("/proc/thread-self/ns/uts", unix.CLONE_NEWUTS)).To(
 					Equal(Ino(utsns, unix.CLONE_NEWUTS)), "uts switch failed")
-			}, netns, utsns)
+			}, netns, utsns)).To(Succeed())
 			Expect(count).To(Equal(1), "fn wasn't called")
 
 			Expect(Ino("/proc/thread-self/ns/net", unix.CLONE_NEWNET)).To(
@@ -82,9 +82,7 @@ var _ = Describe("doing things in different namespaces", Ordered, func() {
 		})
 
 		It("fails when switch fails due to invalid namespace reference", func() {
-			Expect(InterceptGomegaFailure(func() {
-				goInAndOut(func() {}, -1)
-			})).To(MatchError(ContainSubstring("cannot determine type of namespace")))
+			Expect(goInAndOut(func() {}, -1)).To(MatchError(ContainSubstring("cannot determine type of namespace")))
 		})
 
 		It("fails when switch fails due to not being allowed to switch", func() {
@@ -128,11 +126,9 @@ var _ = Describe("doing things in different namespaces", Ordered, func() {
 			Expect(Ino(usernsfd, unix.CLONE_NEWUSER)).NotTo(
 				Equal(CurrentIno(unix.CLONE_NEWUSER)))
 
-			Expect(InterceptGomegaFailure(func() {
-				// Ironically, user namespaces are so zupa zekure that we can't
-				// switch to them because we're multi-threaded at this time.
-				goInAndOut(func() {}, usernsfd)
-			})).To(MatchError(ContainSubstring("cannot switch into user namespace")))
+			// Ironically, user namespaces are so zupa zekure that we can't
+			// switch to them because we're multi-threaded at this time.
+			Expect(goInAndOut(func() {}, usernsfd)).To(MatchError(ContainSubstring("cannot switch into user namespace")))
 		})
 
 		It("fails correctly when unable to switch back", func() {
@@ -146,12 +142,10 @@ var _ = Describe("doing things in different namespaces", Ordered, func() {
 			netns := NewTransient(unix.CLONE_NEWNET)
 
 			count := 0
-			Expect(InterceptGomegaFailure(func() {
-				goInAndOut(func() {
-					count++
-					Expect(caps.SetForThisTask(caps.TaskCapabilities{})).To(Succeed())
-				}, netns)
-			})).To(MatchError(ContainSubstring("cannot restore net namespace")))
+			Expect(goInAndOut(func() {
+				count++
+				Expect(caps.SetForThisTask(caps.TaskCapabilities{})).To(Succeed())
+			}, netns)).To(MatchError(ContainSubstring("cannot restore net namespace")))
 			Expect(count).To(Equal(1))
 		})
 
@@ -199,7 +193,7 @@ var _ = Describe("doing things in different namespaces", Ordered, func() {
 
 			tid := unix.Gettid()
 			count := 0
-			goSeparate(func() {
+			Expect(goSeparate(func() {
 				defer GinkgoRecover()
 				count++
 
@@ -208,14 +202,12 @@ var _ = Describe("doing things in different namespaces", Ordered, func() {
 					Equal(Ino(mntnsfd, syscall.CLONE_NEWNS)))
 				Expect(Ino("/proc/thread-self/ns/net", unix.CLONE_NEWNET)).To(
 					Equal(Ino(netnsfd, syscall.CLONE_NEWNET)))
-			}, mntnsfd, netnsfd)
+			}, mntnsfd, netnsfd)).To(Succeed())
 			Expect(count).To(Equal(1), "fn wasn't called")
 		})
 
 		It("fails when switch fails due to invalid namespace reference", func() {
-			Expect(InterceptGomegaFailure(func() {
-				goSeparate(func() {}, 0)
-			})).To(MatchError(ContainSubstring("cannot switch into mnt namespace")))
+			Expect(goSeparate(func() {}, 0)).To(MatchError(ContainSubstring("cannot switch into mnt namespace")))
 		})
 
 	})
@@ -251,7 +243,7 @@ var _ = Describe("doing things in different namespaces", Ordered, func() {
 					Equal(Ino(ipcns, unix.CLONE_NEWIPC)), "didn't brought over the ipc namespace")
 				Expect(CurrentIno(unix.CLONE_NEWNET)).To(
 					Equal(Ino(netns, unix.CLONE_NEWNET)), "didn't switch the net namespace")
-			}, netns)
+			}, Fd(netns))
 			Expect(count).To(Equal(1), "didn't call fn")
 
 			Expect(Filedescriptors()).NotTo(HaveLeakedFds(goodFds))
@@ -290,19 +282,70 @@ var _ = Describe("doing things in different namespaces", Ordered, func() {
 					Equal(Ino(ipcns, unix.CLONE_NEWIPC)), "didn't brought over the ipc namespace")
 				Expect(CurrentIno(unix.CLONE_NEWNET)).To(
 					Equal(Ino(netns, unix.CLONE_NEWNET)), "didn't switch the net namespace")
-			}, mntns, netns)
+			}, Fd(mntns), Fd(netns))
 			Expect(count).To(Equal(1), "didn't call fn")
 		})
 
 		It("fails when the separate fn go routine fails switching", func() {
 			Expect(InterceptGomegaFailure(func() {
-				Execute(func() {}, Current(unix.CLONE_NEWNS), -1)
-			})).To(MatchError(ContainSubstring("cannot determine type of namespace")))
+				Execute(func() {}, Fd(Current(unix.CLONE_NEWNS)), Fd(-1))
+			})).To(MatchError(ContainSubstring("invalid namespace reference")))
+		})
+
+		It("returns errors instead of failing", func() {
+			Expect(ExecuteE(func() {}, Fd(Current(unix.CLONE_NEWNS)), Fd(-1))).To(
+				MatchError(ContainSubstring("invalid namespace reference")))
+			Expect(ExecuteE(func() {}, Fd(Current(unix.CLONE_NEWUSER)))).To(
+				MatchError("cannot Execute() in different user namespace"))
+
+			netns := NewTransientNamespace(unix.CLONE_NEWNET)
+			count := 0
+			Expect(ExecuteE(func() {
+				count++
+				Expect(netns).To(BeCurrentNamespace())
+			}, netns)).To(Succeed())
+			Expect(count).To(Equal(1))
+			Expect(func() {
+				_ = ExecuteE(func() { panic("D'oh!") }, netns)
+			}).To(PanicWith("D'oh!"))
+		})
+
+		It("returns values and errors, also from mount namespaces", func() {
+			netns := NewTransientNamespace(unix.CLONE_NEWNET)
+			Expect(ExecuteValue(func() uint64 {
+				return CurrentIno(unix.CLONE_NEWNET)
+			}, netns)).To(Equal(netns.Ino()))
+
+			mntns := Current(unix.CLONE_NEWNS)
+			tid := unix.Gettid()
+			Expect(ExecuteValue(unix.Gettid, Fd(mntns))).NotTo(Equal(tid),
+				"didn't execute on a separate thread")
+			Expect(ExecuteErr(func() error { return unix.EPERM }, Fd(mntns), netns)).To(
+				MatchError(unix.EPERM))
+			Expect(func() {
+				_ = ExecuteErr(func() error { panic("D'oh!") }, Fd(mntns))
+			}).To(PanicWith("D'oh!"))
+		})
+
+		It("rejects invalid namespace references", func() {
+			Expect(ExecuteE(func() {}, nil)).To(
+				MatchError(ContainSubstring("got nil")))
+			Expect(ExecuteE(func() {}, (*Namespace)(nil))).To(
+				MatchError(ContainSubstring("invalid namespace reference")))
+			Expect(ExecuteE(func() {}, Fd(-1))).To(
+				MatchError(ContainSubstring("invalid namespace reference")))
+		})
+
+		It("rejects namespaces of the wrong type", func() {
+			netns := CurrentNamespace(unix.CLONE_NEWNET)
+			Expect(ExecuteE(func() {}, Typed(netns, unix.CLONE_NEWNS))).To(
+				MatchError("not a mnt namespace, but net"))
+			Expect(ExecuteE(func() {}, Typed(netns.Fd(), unix.CLONE_NEWNET))).To(Succeed())
 		})
 
 		It("rejects to switch user namespaces", func() {
 			Expect(InterceptGomegaFailure(func() {
-				Execute(func() {}, Current(unix.CLONE_NEWUSER))
+				Execute(func() {}, Fd(Current(unix.CLONE_NEWUSER)))
 			})).To(MatchError(ContainSubstring("cannot Execute() in different user namespace")))
 		})
 
@@ -317,7 +360,7 @@ var _ = Describe("doing things in different namespaces", Ordered, func() {
 				count := 0
 				Execute(func() {
 					count++
-				}, netns)
+				}, Fd(netns))
 				Expect(count).To(Equal(1), "didn't call fn")
 			})
 		})
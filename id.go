@@ -0,0 +1,239 @@
+// Copyright 2025 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spacetest
+
+import (
+	"errors"
+	"fmt"
+	"unsafe"
+
+	"github.com/thediveo/ioctl"
+	"golang.org/x/sys/unix"
+
+	. "github.com/onsi/ginkgo/v2" //nolint:staticcheck // ST1001 rule does not apply
+	. "github.com/onsi/gomega"    //nolint:staticcheck // ST1001 rule does not apply
+)
+
+// NS_GET_ID defines the ioctl request code that returns the 64-bit ID of the
+// namespace referred to by a file descriptor. This ID is unique for the
+// lifetime of the system, as opposed to inode numbers that might get reused.
+var NS_GET_ID = ioctl.IOR(_NSIO, 0xd, 8) //nolint:godoclint // out of touch
+
+// FD_NSFS_ROOT is the special “mount fd” for opening nsfs file handles using
+// open_by_handle_at(2).
+const FD_NSFS_ROOT = -10003 //nolint:godoclint // out of touch
+
+// ID identifies a Linux kernel namespace by the device and inode numbers of
+// its nsfs inode and, where supported by the kernel (Linux 6.18+), by its
+// 64-bit namespace ID. Where not supported, NSID is zero.
+type ID struct {
+	Dev  uint64
+	Ino  uint64
+	NSID uint64
+}
+
+// String returns the textual representation of this namespace identity, such
+// as “[4026531840 on dev 4, id 42]”.
+func (id ID) String() string {
+	if id.NSID == 0 {
+		return fmt.Sprintf("[%d on dev %d]", id.Ino, id.Dev)
+	}
+	return fmt.Sprintf("[%d on dev %d, id %d]", id.Ino, id.Dev, id.NSID)
+}
+
+// Equal returns true if both IDs identify the same namespace. If both IDs
+// have namespace IDs, Equal compares them; otherwise, Equal compares the device
+// and inode numbers.
+func (id ID) Equal(other ID) bool {
+	if id.NSID != 0 && other.NSID != 0 {
+		return id.NSID == other.NSID
+	}
+	return id.Dev == other.Dev && id.Ino == other.Ino
+}
+
+// IDOf returns the identity of the namespace referenced either by a file
+// descriptor, a VFS path name, or a [Namespace].
+//
+// If the specified reference is invalid, IDOf fails the current test.
+func IDOf[R Reference](ref R) ID {
+	GinkgoHelper()
+
+	id, err := IDOfE(ref)
+	Expect(err).NotTo(HaveOccurred())
+	return id
+}
+
+// IDOfE works like [IDOf], but returns an error instead of failing the current
+// test.
+func IDOfE[R Reference](ref R) (ID, error) {
+	if ns, ok := any(ref).(*Namespace); ok {
+		return ns.ID(), nil
+	}
+	var id ID
+	err := withFdE(ref, func(fd int) error {
+		if _, err := typeOfFd(fd); err != nil {
+			return err
+		}
+		var err error
+		id, err = idOfFd(fd)
+		return err
+	})
+	return id, err
+}
+
+// CurrentID returns the identity of the namespace (of the specified type) the
+// OS-level thread is currently attached to.
+func CurrentID(typ int) ID {
+	GinkgoHelper()
+
+	return IDOf("/proc/thread-self/ns/" + Name(typ))
+}
+
+// idOfFd returns the identity of the namespace referenced by the passed file
+// descriptor.
+func idOfFd(fd int) (ID, error) {
+	var namespaceStat unix.Stat_t
+	if err := unix.Fstat(fd, &namespaceStat); err != nil {
+		return ID{}, fmt.Errorf("cannot stat namespace reference %d, reason: %w", fd, err)
+	}
+	return ID{
+		Dev:  namespaceStat.Dev,
+		Ino:  namespaceStat.Ino,
+		NSID: nsidOf(fd),
+	}, nil
+}
+
+// nsidOf returns the 64-bit namespace ID of the namespace referenced by the
+// passed file descriptor, or zero if the kernel doesn't support namespace IDs.
+func nsidOf(fd int) uint64 {
+	var nsid uint64
+	_, _, errno := unix.Syscall(unix.SYS_IOCTL,
+		uintptr(fd), uintptr(NS_GET_ID), uintptr(unsafe.Pointer(&nsid)))
+	if errno != 0 {
+		return 0
+	}
+	return nsid
+}
+
+// FileHandle references a namespace by an nsfs file handle without keeping
+// the namespace alive, so the namespace can later be reopened as long as it
+// still exists. On kernels not supporting nsfs file handles (before Linux
+// 6.18), FileHandle instead falls back to keeping a duplicated file descriptor
+// of the namespace open, until the FileHandle is closed.
+type FileHandle struct {
+	handle   unix.FileHandle
+	fallback *Namespace
+}
+
+// EncodeFileHandle returns a [FileHandle] for the namespace referenced either
+// by a file descriptor, a VFS path name, or a [Namespace]. EncodeFileHandle
+// schedules a DeferCleanup of the returned FileHandle to be closed at the end
+// of the current test.
+//
+// If the specified reference is invalid, EncodeFileHandle fails the current
+// test.
+func EncodeFileHandle[R Reference](ref R) *FileHandle {
+	GinkgoHelper()
+
+	fh, err := EncodeFileHandleE(ref)
+	Expect(err).NotTo(HaveOccurred())
+	DeferCleanup(func() { _ = fh.Close() })
+	return fh
+}
+
+// EncodeFileHandleE works like [EncodeFileHandle], but returns an error instead
+// of failing the current test. The caller is responsible for closing the
+// returned FileHandle.
+func EncodeFileHandleE[R Reference](ref R) (*FileHandle, error) {
+	var fh *FileHandle
+	err := withFdE(ref, func(fd int) error {
+		if _, err := typeOfFd(fd); err != nil {
+			return err
+		}
+		handle, _, err := unix.NameToHandleAt(fd, "", unix.AT_EMPTY_PATH)
+		if err == nil {
+			fh = &FileHandle{handle: handle}
+			return nil
+		}
+		if !errors.Is(err, unix.EOPNOTSUPP) && !errors.Is(err, unix.EINVAL) {
+			return fmt.Errorf("cannot encode namespace file handle, reason: %w", err)
+		}
+		dupfd, err := unix.FcntlInt(uintptr(fd), unix.F_DUPFD_CLOEXEC, 0)
+		if err != nil {
+			return fmt.Errorf("cannot duplicate namespace reference, reason: %w", err)
+		}
+		ns, err := NewNamespace(dupfd)
+		if err != nil {
+			_ = unix.Close(dupfd)
+			return err
+		}
+		fh = &FileHandle{fallback: ns}
+		return nil
+	})
+	return fh, err
+}
+
+// Persistent returns true if this FileHandle is a true nsfs file handle that
+// doesn't keep its namespace alive, and false if it falls back to keeping a
+// file descriptor open.
+func (fh *FileHandle) Persistent() bool {
+	return fh.fallback == nil
+}
+
+// Open returns a new [Namespace] for the namespace referenced by this
+// FileHandle. Open schedules a DeferCleanup of the returned Namespace to be
+// closed at the end of the current test.
+//
+// If the namespace doesn't exist anymore, Open fails the current test.
+func (fh *FileHandle) Open() *Namespace {
+	GinkgoHelper()
+
+	ns, err := fh.OpenE()
+	Expect(err).NotTo(HaveOccurred())
+	DeferCleanup(func() { _ = ns.Close() })
+	return ns
+}
+
+// OpenE works like [FileHandle.Open], but returns an error instead of failing
+// the current test. The caller is responsible for closing the returned
+// Namespace.
+//
+// Opening true nsfs file handles requires the caller to either be attached to
+// the namespace or to have CAP_SYS_ADMIN in the user namespace owning it.
+func (fh *FileHandle) OpenE() (*Namespace, error) {
+	if fh.fallback != nil {
+		return fh.fallback.Dup()
+	}
+	fd, err := unix.OpenByHandleAt(FD_NSFS_ROOT, fh.handle, unix.O_RDONLY|unix.O_CLOEXEC)
+	if err != nil {
+		return nil, fmt.Errorf("cannot open namespace file handle, reason: %w", err)
+	}
+	ns, err := NewNamespace(fd)
+	if err != nil {
+		_ = unix.Close(fd)
+		return nil, err
+	}
+	return ns, nil
+}
+
+// Close releases the namespace file descriptor kept open by a fallback
+// FileHandle; for true nsfs file handles, Close is a no-op. Close can be
+// called multiple times.
+func (fh *FileHandle) Close() error {
+	if fh.fallback == nil {
+		return nil
+	}
+	return fh.fallback.Close()
+}
@@ -0,0 +1,125 @@
+// Copyright 2025 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spacetest
+
+import (
+	"os"
+	"time"
+
+	"golang.org/x/sys/unix"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	. "github.com/thediveo/fdooze"
+	. "github.com/thediveo/success"
+)
+
+var _ = Describe("namespace identities", func() {
+
+	BeforeEach(func() {
+		goodfds := Filedescriptors()
+		DeferCleanup(func() {
+			Expect(Filedescriptors()).NotTo(HaveLeakedFds(goodfds))
+		})
+	})
+
+	It("renders and compares IDs", func() {
+		Expect(ID{Dev: 4, Ino: 42}.String()).To(Equal("[42 on dev 4]"))
+		Expect(ID{Dev: 4, Ino: 42, NSID: 666}.String()).To(Equal("[42 on dev 4, id 666]"))
+
+		Expect(ID{Dev: 4, Ino: 42}.Equal(ID{Dev: 4, Ino: 42, NSID: 666})).To(BeTrue())
+		Expect(ID{Dev: 4, Ino: 42}.Equal(ID{Dev: 5, Ino: 42})).To(BeFalse())
+		Expect(ID{Dev: 4, Ino: 42, NSID: 1}.Equal(ID{Dev: 4, Ino: 42, NSID: 2})).To(BeFalse())
+	})
+
+	It("returns namespace IDs", func() {
+		var st unix.Stat_t
+		Expect(unix.Stat("/proc/self/ns/net", &st)).To(Succeed())
+
+		id := IDOf("/proc/self/ns/net")
+		Expect(id.Dev).To(Equal(st.Dev))
+		Expect(id.Ino).To(Equal(st.Ino))
+		Expect(CurrentID(unix.CLONE_NEWNET).Equal(id)).To(BeTrue())
+
+		netns := OpenNamespace("/proc/self/ns/net")
+		Expect(netns.ID()).To(Equal(id))
+		Expect(IDOf(netns)).To(Equal(id))
+		Expect(IDOf(netns.Fd())).To(Equal(id))
+
+		Expect(IDOf("/proc/self/ns/uts").Equal(id)).To(BeFalse())
+	})
+
+	It("reports errors", func() {
+		Expect(IDOfE("/nonexisting")).Error().To(
+			MatchError(ContainSubstring("cannot open namespace referenced as")))
+		fd := Successful(unix.Open(".", unix.O_RDONLY, 0))
+		defer func() { _ = unix.Close(fd) }()
+		Expect(IDOfE(fd)).Error().To(
+			MatchError(ContainSubstring("cannot determine type of namespace")))
+		Expect(EncodeFileHandleE(fd)).Error().To(
+			MatchError(ContainSubstring("cannot determine type of namespace")))
+	})
+
+	It("encodes and reopens namespace file handles", func() {
+		fh := EncodeFileHandle("/proc/self/ns/net")
+		netns := fh.Open()
+		Expect(netns).To(BeSameNamespaceAs("/proc/self/ns/net"))
+		Expect(fh.Close()).To(Succeed())
+	})
+
+	It("falls back to keeping namespaces open", func() {
+		ns := OpenNamespace("/proc/self/ns/net")
+		fh := &FileHandle{fallback: Successful(ns.Dup())}
+		Expect(fh.Persistent()).To(BeFalse())
+		Expect(fh.Open()).To(BeSameNamespaceAs(ns))
+		Expect(fh.Close()).To(Succeed())
+		Expect(fh.Close()).To(Succeed())
+		Expect(fh.OpenE()).Error().To(HaveOccurred())
+	})
+
+	When("being root", func() {
+
+		BeforeEach(func() {
+			if os.Getuid() != 0 {
+				Skip("needs root")
+			}
+		})
+
+		It("doesn't keep namespaces alive using file handles", func() {
+			netns := Successful(NewTransientNamespaceE(unix.CLONE_NEWNET))
+			fh := EncodeFileHandle(netns)
+			if !fh.Persistent() {
+				_ = netns.Close()
+				Skip("kernel doesn't support nsfs file handles")
+			}
+			Expect(netns.ID().NSID).NotTo(BeZero())
+			reopened := fh.Open()
+			Expect(reopened.ID()).To(Equal(netns.ID()))
+
+			Expect(netns.Close()).To(Succeed())
+			Expect(reopened.Close()).To(Succeed())
+			Eventually(func() error {
+				ns, err := fh.OpenE()
+				if err == nil {
+					_ = ns.Close()
+				}
+				return err
+			}).Within(2 * time.Second).ProbeEvery(10 * time.Millisecond).
+				Should(HaveOccurred())
+		})
+
+	})
+
+})
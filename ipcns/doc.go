@@ -0,0 +1,46 @@
+/*
+Package ipcns supports running unit tests in separated transient IPC
+namespaces, handling cleanup and error checking automatically.
+
+IPC namespaces isolate System V IPC objects (shared memory segments, semaphore
+sets, and message queues) as well as POSIX message queues. Tests of code using
+these IPC objects thus can work with well-known keys and names without clashing
+with the IPC objects of the host.
+
+# Usage
+
+	import "github.com/thediveo/spacetest/ipcns"
+
+	It("tests something inside a transient IPC namespace", func() {
+	  ipcnsfd := ipcns.NewTransient()
+	  shmid := ipcns.NewSharedMemory(ipcnsfd, 0x2a, 4096)
+	  ipcns.NewPOSIXMessageQueue(ipcnsfd, "/frobnicator")
+	  ipcns.Execute(ipcnsfd, func() {
+	    // ...
+	  })
+	})
+
+The IPC object fixtures [NewSharedMemory], [NewSemaphoreSet], [NewMessageQueue],
+and [NewPOSIXMessageQueue] automatically remove the IPC objects they created at
+the end of the current test.
+
+# POSIX Message Queue Filesystem
+
+Similar to sysfs and network namespaces, an “mqueue” filesystem instance shows
+the POSIX message queues of the IPC namespace of the mounting process. In order
+to see the POSIX message queues of a transient IPC namespace in “/dev/mqueue”,
+tests thus need to additionally enter a transient mount namespace and then mount
+a fresh mqueue filesystem instance using [MountMqueue]:
+
+	import (
+	    "github.com/thediveo/spacetest/ipcns"
+	    "github.com/thediveo/spacetest/mntns"
+	)
+
+	It("mounts a fresh mqueue filesystem", func() {
+	    defer ipcns.EnterTransient()()
+	    defer mntns.EnterTransient()()
+	    ipcns.MountMqueue()
+	})
+*/
+package ipcns
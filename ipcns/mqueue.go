@@ -0,0 +1,85 @@
+// Copyright 2025 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ipcns
+
+import (
+	"strings"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+
+	"github.com/thediveo/spacetest"
+	"github.com/thediveo/spacetest/mntns"
+
+	. "github.com/onsi/ginkgo/v2" //nolint:staticcheck // ST1001 rule does not apply
+	. "github.com/onsi/gomega"    //nolint:staticcheck // ST1001 rule does not apply
+)
+
+// NewPOSIXMessageQueue creates a new POSIX message queue with the specified
+// name inside the IPC namespace referenced by ipcnsfd. The name must be in the
+// form of “/somename”, see also [mq_overview(7)].
+//
+// Additionally, NewPOSIXMessageQueue schedules a [DeferCleanup] that unlinks
+// the message queue at the end of the current test.
+//
+// [mq_overview(7)]: https://man7.org/linux/man-pages/man7/mq_overview.7.html
+func NewPOSIXMessageQueue[H spacetest.Handle](ipcnsfd H, name string) {
+	GinkgoHelper()
+
+	// The glibc mq_open(3) wrapper strips the leading slash from the name, so
+	// we need to do the same when directly calling into the kernel.
+	qname, ok := strings.CutPrefix(name, "/")
+	Expect(ok && qname != "" && !strings.Contains(qname, "/")).To(BeTrue(),
+		"invalid POSIX message queue name %q", name)
+	qnameptr, err := unix.BytePtrFromString(qname)
+	Expect(err).NotTo(HaveOccurred(), "invalid POSIX message queue name %q", name)
+
+	spacetest.Execute(func() {
+		mqd, _, errno := unix.Syscall6(unix.SYS_MQ_OPEN,
+			uintptr(unsafe.Pointer(qnameptr)),
+			uintptr(unix.O_RDWR|unix.O_CREAT|unix.O_EXCL|unix.O_CLOEXEC),
+			0o600, 0, 0, 0)
+		Expect(errno).To(BeZero(), "cannot create POSIX message queue %q", name)
+		// The message queue stays in existence until it gets unlinked, so we
+		// don't need to keep the queue descriptor open.
+		_ = unix.Close(int(mqd))
+	}, spacetest.Typed(ipcnsfd, unix.CLONE_NEWIPC))
+	DeferCleanup(func() {
+		spacetest.Execute(func() {
+			_, _, errno := unix.Syscall(unix.SYS_MQ_UNLINK,
+				uintptr(unsafe.Pointer(qnameptr)), 0, 0)
+			Expect(errno).To(BeZero(), "cannot unlink POSIX message queue %q", name)
+		}, spacetest.Typed(ipcnsfd, unix.CLONE_NEWIPC))
+	})
+}
+
+// MountMqueue mounts a new “mqueue” filesystem instance onto “/dev/mqueue” when
+// the caller is in a new and transient mount namespace. Otherwise, MountMqueue
+// will fail the current test. The new mqueue filesystem instance shows the
+// POSIX message queues of the IPC namespace the caller is attached to.
+func MountMqueue() {
+	GinkgoHelper()
+
+	// Ensure that we're not still in the process's original mount namespace, as
+	// otherwise we would overmount the host's /dev/mqueue.
+	Expect(mntns.CurrentIno()).NotTo(Equal(mntns.Ino("/proc/self/ns/mnt")),
+		"current mount namespace must not be the process's original mount namespace")
+
+	Expect(unix.Mount(
+		"mqueue", "/dev/mqueue", "mqueue",
+		unix.MS_NODEV|unix.MS_NOEXEC|unix.MS_NOSUID|unix.MS_RELATIME,
+		"")).To(Succeed(),
+		"cannot mount new mqueue instance on /dev/mqueue")
+}
@@ -0,0 +1,68 @@
+// Copyright 2025 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ipcns
+
+import (
+	"os"
+
+	"github.com/thediveo/spacetest/mntns"
+	"golang.org/x/sys/unix"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	. "github.com/thediveo/success"
+)
+
+var _ = Describe("POSIX message queues", func() {
+
+	BeforeEach(func() {
+		if os.Getuid() != 0 {
+			Skip("needs root")
+		}
+	})
+
+	It("rejects invalid message queue names", func() {
+		ipcns := NewTransient()
+		Expect(InterceptGomegaFailure(func() {
+			NewPOSIXMessageQueue(ipcns, "frobnicator")
+		})).To(MatchError(ContainSubstring("invalid POSIX message queue name")))
+		Expect(InterceptGomegaFailure(func() {
+			NewPOSIXMessageQueue(ipcns, "/frob/nicator")
+		})).To(MatchError(ContainSubstring("invalid POSIX message queue name")))
+	})
+
+	It("rejects mounting mqueue in the original mount namespace", func() {
+		Expect(InterceptGomegaFailure(func() {
+			MountMqueue()
+		})).To(MatchError(
+			ContainSubstring("current mount namespace must not be the process's original mount namespace")))
+	})
+
+	It("creates a POSIX message queue and shows it in a fresh mqueue instance", func() {
+		defer EnterTransient()()
+		NewPOSIXMessageQueue(Current(), "/frobnicator")
+
+		defer mntns.EnterTransient()()
+		// Don't rely on /dev/mqueue being present; instead, overmount /dev in
+		// our transient mount namespace and provide our own mount point.
+		Expect(unix.Mount("none", "/dev", "tmpfs", 0, "")).To(Succeed())
+		Expect(os.Mkdir("/dev/mqueue", 0o755)).To(Succeed())
+		MountMqueue()
+
+		Expect(Successful(os.ReadDir("/dev/mqueue"))).To(
+			ConsistOf(HaveField("Name()", "frobnicator")))
+	})
+
+})
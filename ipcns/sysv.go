@@ -0,0 +1,131 @@
+// Copyright 2025 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ipcns
+
+import (
+	"golang.org/x/sys/unix"
+
+	"github.com/thediveo/spacetest"
+
+	. "github.com/onsi/ginkgo/v2" //nolint:staticcheck // ST1001 rule does not apply
+	. "github.com/onsi/gomega"    //nolint:staticcheck // ST1001 rule does not apply
+)
+
+// NewSharedMemory creates a new System V shared memory segment of the specified
+// size (in bytes) and with the specified key inside the IPC namespace
+// referenced by ipcnsfd, returning the segment's identifier. Pass
+// [unix.IPC_PRIVATE] as the key in order to create a segment without key.
+//
+// Additionally, NewSharedMemory schedules a [DeferCleanup] that removes the
+// shared memory segment at the end of the current test.
+func NewSharedMemory[H spacetest.Handle](ipcnsfd H, key int, size int) int {
+	GinkgoHelper()
+
+	return newSysvObject(ipcnsfd, "shared memory segment",
+		func() (int, error) {
+			return unix.SysvShmGet(key, size, unix.IPC_CREAT|unix.IPC_EXCL|0o600)
+		},
+		func(id int) error {
+			_, err := unix.SysvShmCtl(id, unix.IPC_RMID, nil)
+			return err
+		})
+}
+
+// NewSemaphoreSet creates a new System V semaphore set with nsems semaphores
+// and with the specified key inside the IPC namespace referenced by ipcnsfd,
+// returning the semaphore set's identifier. Pass [unix.IPC_PRIVATE] as the key
+// in order to create a semaphore set without key.
+//
+// Additionally, NewSemaphoreSet schedules a [DeferCleanup] that removes the
+// semaphore set at the end of the current test.
+func NewSemaphoreSet[H spacetest.Handle](ipcnsfd H, key int, nsems int) int {
+	GinkgoHelper()
+
+	return newSysvObject(ipcnsfd, "semaphore set",
+		func() (int, error) {
+			id, _, errno := unix.Syscall(unix.SYS_SEMGET,
+				uintptr(key), uintptr(nsems), uintptr(unix.IPC_CREAT|unix.IPC_EXCL|0o600))
+			if errno != 0 {
+				return -1, errno
+			}
+			return int(id), nil
+		},
+		func(id int) error {
+			_, _, errno := unix.Syscall6(unix.SYS_SEMCTL,
+				uintptr(id), 0, uintptr(unix.IPC_RMID), 0, 0, 0)
+			if errno != 0 {
+				return errno
+			}
+			return nil
+		})
+}
+
+// NewMessageQueue creates a new System V message queue with the specified key
+// inside the IPC namespace referenced by ipcnsfd, returning the message
+// queue's identifier. Pass [unix.IPC_PRIVATE] as the key in order to create a
+// message queue without key.
+//
+// Additionally, NewMessageQueue schedules a [DeferCleanup] that removes the
+// message queue at the end of the current test.
+func NewMessageQueue[H spacetest.Handle](ipcnsfd H, key int) int {
+	GinkgoHelper()
+
+	return newSysvObject(ipcnsfd, "message queue",
+		func() (int, error) {
+			id, _, errno := unix.Syscall(unix.SYS_MSGGET,
+				uintptr(key), uintptr(unix.IPC_CREAT|unix.IPC_EXCL|0o600), 0)
+			if errno != 0 {
+				return -1, errno
+			}
+			return int(id), nil
+		},
+		func(id int) error {
+			_, _, errno := unix.Syscall(unix.SYS_MSGCTL,
+				uintptr(id), uintptr(unix.IPC_RMID), 0)
+			if errno != 0 {
+				return errno
+			}
+			return nil
+		})
+}
+
+// newSysvObject creates a new System V IPC object using the passed create
+// function while attached to the IPC namespace referenced by ipcnsfd. It then
+// schedules a DeferCleanup to remove the IPC object again using the passed
+// remove function, again while attached to the IPC namespace referenced by
+// ipcnsfd.
+func newSysvObject[H spacetest.Handle](
+	ipcnsfd H,
+	what string,
+	create func() (int, error),
+	remove func(id int) error,
+) int {
+	GinkgoHelper()
+
+	id := -1
+	spacetest.Execute(func() {
+		var err error
+		id, err = create()
+		Expect(err).NotTo(HaveOccurred(), "cannot create System V %s", what)
+	}, spacetest.Typed(ipcnsfd, unix.CLONE_NEWIPC))
+	// As NewTransient schedules its cleanup before we're scheduling ours, we
+	// will run before the IPC namespace reference gets closed.
+	DeferCleanup(func() {
+		spacetest.Execute(func() {
+			Expect(remove(id)).To(Succeed(), "cannot remove System V %s %d", what, id)
+		}, spacetest.Typed(ipcnsfd, unix.CLONE_NEWIPC))
+	})
+	return id
+}
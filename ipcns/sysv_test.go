@@ -0,0 +1,89 @@
+// Copyright 2025 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ipcns
+
+import (
+	"os"
+
+	"golang.org/x/sys/unix"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	. "github.com/thediveo/success"
+)
+
+const testKey = 0x5ace
+
+var _ = Describe("System V IPC objects", func() {
+
+	BeforeEach(func() {
+		if os.Getuid() != 0 {
+			Skip("needs root")
+		}
+	})
+
+	It("creates a shared memory segment in a transient IPC namespace", func() {
+		ipcns := NewTransient()
+		shmid := NewSharedMemory(ipcns, testKey, 4096)
+		Execute(ipcns, func() {
+			Expect(unix.SysvShmGet(testKey, 0, 0)).To(Equal(shmid))
+		})
+		_, err := unix.SysvShmGet(testKey, 0, 0)
+		Expect(err).To(MatchError(unix.ENOENT))
+	})
+
+	It("creates a semaphore set in a transient IPC namespace", func() {
+		ipcns := NewTransient()
+		semid := NewSemaphoreSet(ipcns, testKey, 2)
+		Execute(ipcns, func() {
+			id, _, errno := unix.Syscall(unix.SYS_SEMGET, testKey, 0, 0)
+			Expect(errno).To(BeZero())
+			Expect(int(id)).To(Equal(semid))
+		})
+		_, _, errno := unix.Syscall(unix.SYS_SEMGET, testKey, 0, 0)
+		Expect(errno).To(Equal(unix.ENOENT))
+	})
+
+	It("creates a message queue in a transient IPC namespace", func() {
+		ipcns := NewTransient()
+		msqid := NewMessageQueue(ipcns, testKey)
+		Execute(ipcns, func() {
+			id, _, errno := unix.Syscall(unix.SYS_MSGGET, testKey, 0, 0)
+			Expect(errno).To(BeZero())
+			Expect(int(id)).To(Equal(msqid))
+		})
+		_, _, errno := unix.Syscall(unix.SYS_MSGGET, testKey, 0, 0)
+		Expect(errno).To(Equal(unix.ENOENT))
+	})
+
+	It("removes IPC objects", func() {
+		ipcns := NewTransient()
+		var shmid int
+		// Schedule our check before NewSharedMemory schedules its removal, so
+		// that our check runs after the IPC object should have been removed.
+		DeferCleanup(func() {
+			Execute(ipcns, func() {
+				_, err := unix.SysvShmCtl(shmid, unix.IPC_STAT, &unix.SysvShmDesc{})
+				Expect(err).To(HaveOccurred())
+			})
+		})
+		shmid = NewSharedMemory(ipcns, unix.IPC_PRIVATE, 4096)
+		Execute(ipcns, func() {
+			Expect(Successful(unix.SysvShmCtl(shmid, unix.IPC_STAT, &unix.SysvShmDesc{}))).
+				To(BeZero())
+		})
+	})
+
+})
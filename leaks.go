@@ -0,0 +1,222 @@
+// Copyright 2025 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spacetest
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/onsi/gomega/gcustom"
+	"github.com/onsi/gomega/types"
+)
+
+// HeldNamespace describes a Linux kernel namespace kept alive by the process,
+// together with what is holding it: an open file descriptor, a thread attached
+// to it, or a bind mount.
+type HeldNamespace struct {
+	Type   int    // type of namespace as a CLONE_NEW* constant.
+	ID     ID     // identity of the namespace.
+	Holder string // what keeps the namespace alive, such as “fd 17”.
+}
+
+// Namespace returns the textual representation of the held namespace in the
+// same form as the links in /proc/$PID/ns, such as “net:[4026531840]”.
+func (h HeldNamespace) Namespace() string {
+	return fmt.Sprintf("%s:[%d]", Name(h.Type), h.ID.Ino)
+}
+
+// String returns a textual description of the held namespace and what is
+// holding it, such as “net:[4026531840] held by fd 17”.
+func (h HeldNamespace) String() string {
+	return h.Namespace() + " held by " + h.Holder
+}
+
+// nsLinkRegexp matches the textual representation of namespaces, such as
+// “net:[4026531840]”, capturing the type name and inode number.
+var nsLinkRegexp = regexp.MustCompile(`^([a-z]+):\[(\d+)\]$`)
+
+// HeldNamespaces returns a snapshot of all Linux kernel namespaces the process
+// keeps alive, through its open file descriptors, the namespaces its threads
+// are attached to, and bind-mounted namespaces in the mount namespaces its
+// threads are attached to.
+//
+// Use HeldNamespaces together with [HaveLeakedNamespaces] to check for leaked
+// namespaces, similar to checking for leaked file descriptors:
+//
+//	BeforeEach(func() {
+//	    goodns := spacetest.HeldNamespaces()
+//	    DeferCleanup(func() {
+//	        Eventually(spacetest.HeldNamespaces).ShouldNot(
+//	            spacetest.HaveLeakedNamespaces(goodns))
+//	    })
+//	})
+//
+// As threads might come and go while taking the snapshot, HeldNamespaces
+// silently skips any entries that have vanished in the meantime.
+func HeldNamespaces() []HeldNamespace {
+	var held []HeldNamespace
+
+	// open file descriptors referencing namespaces.
+	fdentries, _ := os.ReadDir("/proc/self/fd")
+	for _, fdentry := range fdentries {
+		if ns, ok := heldNamespaceAt(filepath.Join("/proc/self/fd", fdentry.Name())); ok {
+			ns.Holder = "fd " + fdentry.Name()
+			held = append(held, ns)
+		}
+	}
+
+	// namespaces the threads are attached to, as well as bind-mounted
+	// namespaces in the mount namespaces of these threads.
+	scannedMntns := map[ID]struct{}{}
+	taskentries, _ := os.ReadDir("/proc/self/task")
+	for _, taskentry := range taskentries {
+		tid := taskentry.Name()
+		nsdir := filepath.Join("/proc/self/task", tid, "ns")
+		nsentries, _ := os.ReadDir(nsdir)
+		for _, nsentry := range nsentries {
+			ns, ok := heldNamespaceAt(filepath.Join(nsdir, nsentry.Name()))
+			if !ok {
+				continue
+			}
+			ns.Holder = "thread " + tid
+			if strings.HasSuffix(nsentry.Name(), "_for_children") {
+				ns.Holder += " (" + nsentry.Name() + ")"
+			}
+			held = append(held, ns)
+			if nsentry.Name() != "mnt" {
+				continue
+			}
+			if _, ok := scannedMntns[ns.ID]; ok {
+				continue
+			}
+			scannedMntns[ns.ID] = struct{}{}
+			held = append(held,
+				heldNamespaceMounts(filepath.Join("/proc/self/task", tid))...)
+		}
+	}
+	return held
+}
+
+// heldNamespaceAt returns the namespace referenced by the symbolic link at the
+// specified path, or false if the link doesn't reference a namespace (anymore).
+func heldNamespaceAt(path string) (HeldNamespace, bool) {
+	link, err := os.Readlink(path)
+	if err != nil {
+		return HeldNamespace{}, false
+	}
+	ns, ok := parseHeldNamespace(link)
+	if !ok {
+		return HeldNamespace{}, false
+	}
+	return withID(ns, path)
+}
+
+// withID returns the passed held namespace with its full identity determined
+// from the namespace at the specified path, or false if the namespace at the
+// path has vanished in the meantime.
+func withID(ns HeldNamespace, path string) (HeldNamespace, bool) {
+	id, err := IDOfE(path)
+	if err != nil || id.Ino != ns.ID.Ino {
+		return HeldNamespace{}, false
+	}
+	ns.ID = id
+	return ns, true
+}
+
+// parseHeldNamespace returns the namespace described textually in the form of
+// “net:[4026531840]”, or false if not a namespace. As the textual form only
+// contains the inode number, the ID of the returned namespace is incomplete.
+func parseHeldNamespace(s string) (HeldNamespace, bool) {
+	m := nsLinkRegexp.FindStringSubmatch(s)
+	if m == nil {
+		return HeldNamespace{}, false
+	}
+	typ, ok := typeOf(m[1])
+	if !ok {
+		return HeldNamespace{}, false
+	}
+	ino, err := strconv.ParseUint(m[2], 10, 64)
+	if err != nil {
+		return HeldNamespace{}, false
+	}
+	return HeldNamespace{Type: typ, ID: ID{Ino: ino}}, true
+}
+
+// heldNamespaceMounts returns the namespaces bind-mounted in the mount
+// namespace of the thread with the specified procfs directory.
+func heldNamespaceMounts(taskdir string) []HeldNamespace {
+	mounts, err := ReadMountInfoE(filepath.Join(taskdir, "mountinfo"))
+	if err != nil {
+		return nil
+	}
+	var held []HeldNamespace
+	for _, mount := range mounts {
+		// For bind-mounted namespaces, the root field is the textual
+		// namespace representation, such as "net:[4026531840]".
+		if mount.FSType != "nsfs" {
+			continue
+		}
+		ns, ok := parseHeldNamespace(mount.Root)
+		if !ok {
+			continue
+		}
+		ns, ok = withID(ns, filepath.Join(taskdir, "root", mount.MountPoint))
+		if !ok {
+			continue
+		}
+		ns.Holder = "bind mount " + mount.MountPoint
+		held = append(held, ns)
+	}
+	return held
+}
+
+// HaveLeakedNamespaces succeeds if actual, a snapshot of held namespaces as
+// returned by [HeldNamespaces], contains namespaces that are not present in the
+// passed snapshot of good namespaces taken before. In this case, the failure
+// message lists all the holders of the leaked namespaces, such as “leaked
+// net:[4026532xxx] held by fd 17”.
+//
+// Namespaces that already have been kept alive before are never considered to
+// be leaked, even if they are now held by additional holders. Namespaces are
+// compared using their [ID]s, see also [ID.Equal]. Use
+// [github.com/thediveo/fdooze] to additionally check for leaked file
+// descriptors.
+func HaveLeakedNamespaces(good []HeldNamespace) types.GomegaMatcher {
+	details := &leakDetails{}
+	return gcustom.MakeMatcher(func(actual []HeldNamespace) (bool, error) {
+		details.Leaks = nil
+		for _, ns := range actual {
+			if slices.ContainsFunc(good, func(goodns HeldNamespace) bool {
+				return goodns.Type == ns.Type && goodns.ID.Equal(ns.ID)
+			}) {
+				continue
+			}
+			details.Leaks = append(details.Leaks, "leaked "+ns.String())
+		}
+		slices.Sort(details.Leaks)
+		return len(details.Leaks) > 0, nil
+	}).WithTemplate("Expected namespaces\n{{.To}} have leaked{{range .Data.Leaks}}\n    {{.}}{{end}}",
+		details)
+}
+
+// leakDetails passes the leaked namespaces to the failure message template.
+type leakDetails struct {
+	Leaks []string
+}
@@ -0,0 +1,113 @@
+// Copyright 2025 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spacetest
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+
+	"golang.org/x/sys/unix"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	. "github.com/thediveo/success"
+)
+
+var _ = Describe("namespace leaks", func() {
+
+	It("parses textual namespace representations", func() {
+		ns, ok := parseHeldNamespace("net:[4026531840]")
+		Expect(ok).To(BeTrue())
+		Expect(ns).To(Equal(HeldNamespace{Type: unix.CLONE_NEWNET, ID: ID{Ino: 4026531840}}))
+		_, ok = parseHeldNamespace("pipe:[42]")
+		Expect(ok).To(BeFalse())
+		_, ok = parseHeldNamespace("/dev/null")
+		Expect(ok).To(BeFalse())
+	})
+
+	It("snapshots the namespaces of threads", func() {
+		Expect(HeldNamespaces()).To(ContainElement(HeldNamespace{
+			Type:   unix.CLONE_NEWNET,
+			ID:     IDOf("/proc/self/ns/net"),
+			Holder: fmt.Sprintf("thread %d", os.Getpid()),
+		}))
+	})
+
+	When("creating namespaces", func() {
+
+		BeforeEach(func() {
+			if os.Getuid() != 0 {
+				Skip("needs root")
+			}
+		})
+
+		It("detects namespaces leaked by fds", func() {
+			good := HeldNamespaces()
+			Expect(HeldNamespaces()).NotTo(HaveLeakedNamespaces(good))
+
+			nsfd := Successful(NewTransientE(unix.CLONE_NEWNET))
+			defer func() { _ = unix.Close(nsfd) }()
+
+			m := HaveLeakedNamespaces(good)
+			Expect(m.Match(HeldNamespaces())).To(BeTrue())
+			Expect(m.NegatedFailureMessage(nil)).To(Equal(fmt.Sprintf(
+				"Expected namespaces\nnot to have leaked\n    leaked net:[%d] held by fd %d",
+				Ino(nsfd, unix.CLONE_NEWNET), nsfd)))
+
+			Expect(unix.Close(nsfd)).To(Succeed())
+			Expect(HeldNamespaces()).NotTo(HaveLeakedNamespaces(good))
+		})
+
+		It("detects namespaces leaked by threads and bind mounts", func() {
+			good := HeldNamespaces()
+
+			netns := NewTransientNamespace(unix.CLONE_NEWNET)
+			bindmount := filepath.Join(GinkgoT().TempDir(), "netns")
+			Expect(os.WriteFile(bindmount, nil, 0o644)).To(Succeed())
+
+			done := make(chan struct{})
+			ready := make(chan int)
+			go func() {
+				defer GinkgoRecover()
+				runtime.LockOSThread() // never unlock, as this thread is going to be tainted
+
+				Expect(unix.Unshare(unix.CLONE_FS | unix.CLONE_NEWNS)).To(Succeed())
+				Expect(unix.Mount("none", "/", "", unix.MS_REC|unix.MS_PRIVATE, "")).To(Succeed())
+				Expect(unix.Mount(fmt.Sprintf("/proc/self/fd/%d", netns.Fd()), bindmount, "", unix.MS_BIND, "")).
+					To(Succeed())
+				ready <- unix.Gettid()
+				<-done
+			}()
+			tid := <-ready
+			Expect(netns.Close()).To(Succeed())
+
+			leaks := []string{}
+			for _, ns := range HeldNamespaces() {
+				leaks = append(leaks, ns.String())
+			}
+			Expect(leaks).To(ContainElements(
+				MatchRegexp(`^mnt:\[\d+\] held by thread %d$`, tid),
+				MatchRegexp(`^net:\[\d+\] held by bind mount %s$`, bindmount)))
+			Expect(HeldNamespaces()).To(HaveLeakedNamespaces(good))
+
+			close(done)
+			Eventually(HeldNamespaces).ShouldNot(HaveLeakedNamespaces(good))
+		})
+
+	})
+
+})
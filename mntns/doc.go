@@ -64,6 +64,79 @@ the need to enter it. In case you need to work with absolute symbolic links,
 [procfsroot] will help by resolving absolute symbolic links inside a different
 mount namespace correctly; please refer to the procfsroot package for details.
 
+# Mount Point Propagation
+
+By default, [EnterTransient] and [NewTransient] set the propagation of all
+mount points in the new mount namespace to [Private]. Tests verifying mount
+point propagation itself can instead use the [WithPropagation] and
+[WithSubtreePropagation] options to pick [Slave], [Shared], or [Unbindable]
+propagation for the whole tree or selected subtrees:
+
+	defer mntns.EnterTransient(
+	    mntns.WithSubtreePropagation("/mnt", mntns.Slave))()
+
+[ForTransientSet] applies these options to the new mount namespace of a set of
+transient namespaces created by [spacetest.NewTransientSet] or
+[spacetest.EnterTransientSet].
+
+# Mounting
+
+[MountTmpfs], [BindMount], [MountProcfs], and the generic [Mount] take care of
+the usual mount boilerplate. Same as [MountSysfsRO], they refuse to mount
+anything while the caller is still in the process's original mount namespace.
+They also refuse to mount onto mount points with [Shared] propagation that are
+peers of mount points in the original mount namespace, as the new mounts then
+would propagate back into the host. At the end of the current test, the mounts
+get automatically unmounted in reverse order:
+
+	It("bind mounts a fixture read-only", func() {
+	    defer mntns.EnterTransient()()
+	    mntns.MountTmpfs("/mnt")
+	    mntns.BindMount("testdata/fixture", "/mnt", mntns.WithReadOnly())
+	})
+
+# Overlay'ed Root
+
+[NewOverlayRoot] creates a transient mount namespace with an overlay filesystem
+over “/”, so that tests can write anywhere, such as into “/etc”, without
+touching the host. It returns the tmpfs-based upper directory, so that tests
+can check what has been written:
+
+	It("writes to /etc", func() {
+	    mntnsfd, procfsroot, upperdir := mntns.NewOverlayRoot()
+	    mntns.Execute(mntnsfd, func() {
+	        Expect(os.WriteFile("/etc/foo", []byte("bar"), 0o644)).To(Succeed())
+	    })
+	    Expect(filepath.Join(procfsroot, upperdir, "etc/foo")).To(BeARegularFile())
+	})
+
+# Sandboxes
+
+[NewSandboxRoot] creates a transient mount namespace with a directory tree as
+its new root, such as a minimal root filesystem fixture, pivoting into it and
+mounting “/proc”, “/sys”, and a minimal “/dev”. [ExecuteInSandbox] creates such
+a sandbox only for the duration of executing a function inside it:
+
+	It("runs in a sandbox", func() {
+	    mntns.ExecuteInSandbox("testdata/rootfs", func() {
+	        Expect("/etc/os-release").To(BeARegularFile())
+	    })
+	})
+
+# Inspecting Mounts
+
+[MountInfoOf] and [MountInfoAt] return the mounts of a mount namespace,
+referenced either by a file descriptor or a procfsroot path. Where supported,
+they use the listmount(2) and statmount(2) syscalls, falling back to parsing
+mountinfo otherwise. The [HaveMountPoint] and [HavePropagation] matchers then
+allow asserting on mounts without any manual parsing:
+
+	mntnsfd, procfsroot := mntns.NewTransient()
+	mntns.Execute(mntnsfd, func() { mntns.MountSysfsRO() })
+	Expect(procfsroot).To(mntns.HaveMountPoint("/sys").
+	    WithFSType("sysfs").WithOptions("ro"))
+	Expect(procfsroot).To(mntns.HavePropagation("/", "private"))
+
 [sysfs(5)]: https://man7.org/linux/man-pages/man5/sysfs.5.html
 [answer to Switching into a network namespace does not change /sys/class/net?]: https://unix.stackexchange.com/a/457384/288012
 [procfsroot]: https://github.com/thediveo/procfsroot
@@ -16,17 +16,49 @@ package mntns
 
 import (
 	"github.com/thediveo/spacetest"
+	"golang.org/x/sys/unix"
 
 	gi "github.com/onsi/ginkgo/v2"
 )
 
 // Execute fn synchronously in the mount namespace referenced by the open file
-// descriptor mntnsfd.
+// descriptor or [spacetest.Namespace] mntnsfd.
+//
+// If mntnsfd doesn't reference a mount namespace, Execute fails the current
+// test.
 //
 // This is a convenience wrapper for [spacetest.Execute]; the latter allows to
 // specify multiple namespaces to switch into in a single Execute.
-func Execute(mntnsfd int, fn func()) {
+func Execute[H spacetest.Handle](mntnsfd H, fn func()) {
+	gi.GinkgoHelper()
+
+	spacetest.Execute(fn, spacetest.Typed(mntnsfd, unix.CLONE_NEWNS))
+}
+
+// ExecuteE works like [Execute], but returns an error instead of failing the
+// current test in case switching into the mount namespace fails.
+//
+// This is a convenience wrapper for [spacetest.ExecuteE].
+func ExecuteE[H spacetest.Handle](mntnsfd H, fn func()) error {
+	return spacetest.ExecuteE(fn, spacetest.Typed(mntnsfd, unix.CLONE_NEWNS))
+}
+
+// ExecuteValue works like [Execute], but returns the value returned by the
+// passed fn.
+//
+// This is a convenience wrapper for [spacetest.ExecuteValue].
+func ExecuteValue[T any, H spacetest.Handle](mntnsfd H, fn func() T) T {
+	gi.GinkgoHelper()
+
+	return spacetest.ExecuteValue(fn, spacetest.Typed(mntnsfd, unix.CLONE_NEWNS))
+}
+
+// ExecuteErr works like [Execute], but returns the error returned by the
+// passed fn.
+//
+// This is a convenience wrapper for [spacetest.ExecuteErr].
+func ExecuteErr[H spacetest.Handle](mntnsfd H, fn func() error) error {
 	gi.GinkgoHelper()
 
-	spacetest.Execute(fn, mntnsfd)
+	return spacetest.ExecuteErr(fn, spacetest.Typed(mntnsfd, unix.CLONE_NEWNS))
 }
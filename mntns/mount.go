@@ -0,0 +1,276 @@
+// Copyright 2025 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mntns
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+
+	"github.com/thediveo/spacetest"
+	"golang.org/x/sys/unix"
+
+	. "github.com/onsi/ginkgo/v2" //nolint:staticcheck // ST1001 rule does not apply
+	. "github.com/onsi/gomega"    //nolint:staticcheck // ST1001 rule does not apply
+)
+
+// Mount mounts the filesystem specified by source and fstype onto target, using
+// the specified mount flags and filesystem-specific data, see also [mount(2)].
+// Mount fails the current test if the caller is still in the process's
+// original mount namespace, in order to not accidentally overmount parts of the
+// host. For the same reason, Mount also fails if target is located on a mount
+// point with [Shared] propagation that is a peer of a mount point in the
+// process's original mount namespace, as the new mount then would propagate
+// back into the host.
+//
+// Mount schedules a [DeferCleanup] to unmount target at the end of the current
+// test. As Ginkgo runs deferred cleanups in reverse order, mounts stacked on
+// top of each other get unmounted in the correct order. The unmount takes place
+// in the mount namespace the caller was in when calling Mount, even if the
+// caller left this mount namespace in the meantime.
+//
+// Mount is a thin wrapper around [MountE], failing the current test in case
+// MountE returns an error.
+//
+// [mount(2)]: https://man7.org/linux/man-pages/man2/mount.2.html
+func Mount(source, target, fstype string, flags uintptr, data string) {
+	GinkgoHelper()
+
+	unmount, err := MountE(source, target, fstype, flags, data)
+	Expect(err).NotTo(HaveOccurred())
+	DeferCleanup(unmount)
+}
+
+// MountE works like [Mount], but instead of failing the current test it
+// returns an error in case the filesystem cannot be mounted. MountE thus can
+// also be used outside Ginkgo tests.
+//
+// Instead of scheduling any cleanup, MountE returns an unmount function that
+// the caller must call in order to unmount target again.
+func MountE(source, target, fstype string, flags uintptr, data string) (unmount func() error, err error) {
+	return mount(target, 0, func(target string) error {
+		if err := unix.Mount(source, target, fstype, flags, data); err != nil {
+			return fmt.Errorf("cannot mount %s filesystem on %s: %w", fstype, target, err)
+		}
+		return nil
+	})
+}
+
+// MountTmpfs mounts a new “tmpfs” instance onto target, such as a scratch
+// directory, with the same restrictions and automatic unmounting as [Mount].
+//
+// MountTmpfs is a thin wrapper around [MountTmpfsE], failing the current test in
+// case MountTmpfsE returns an error.
+func MountTmpfs(target string) {
+	GinkgoHelper()
+
+	unmount, err := MountTmpfsE(target)
+	Expect(err).NotTo(HaveOccurred())
+	DeferCleanup(unmount)
+}
+
+// MountTmpfsE works like [MountTmpfs], but returns an error instead of failing
+// the current test, and an unmount function instead of scheduling a cleanup.
+func MountTmpfsE(target string) (unmount func() error, err error) {
+	return MountE("tmpfs", target, "tmpfs",
+		unix.MS_NODEV|unix.MS_NOSUID|unix.MS_RELATIME, "")
+}
+
+// MountProcfs mounts a new “proc” filesystem instance onto target, with the
+// same restrictions and automatic unmounting as [Mount]. Please note that the
+// new procfs instance reflects the PID namespace the caller's OS-level thread
+// is a member of.
+//
+// MountProcfs is a thin wrapper around [MountProcfsE], failing the current test
+// in case MountProcfsE returns an error.
+func MountProcfs(target string) {
+	GinkgoHelper()
+
+	unmount, err := MountProcfsE(target)
+	Expect(err).NotTo(HaveOccurred())
+	DeferCleanup(unmount)
+}
+
+// MountProcfsE works like [MountProcfs], but returns an error instead of
+// failing the current test, and an unmount function instead of scheduling a
+// cleanup.
+func MountProcfsE(target string) (unmount func() error, err error) {
+	return MountE("proc", target, "proc",
+		unix.MS_NODEV|unix.MS_NOEXEC|unix.MS_NOSUID|unix.MS_RELATIME, "")
+}
+
+// BindOption configures a bind mount created by [BindMount] or [BindMountE].
+type BindOption func(*bindOptions)
+
+type bindOptions struct {
+	readonly  bool
+	recursive bool
+}
+
+// WithReadOnly makes a bind mount read-only. Please note that for recursive
+// bind mounts only the topmost bind mount becomes read-only.
+func WithReadOnly() BindOption {
+	return func(o *bindOptions) { o.readonly = true }
+}
+
+// WithRecursive additionally bind mounts all mounts below source.
+func WithRecursive() BindOption {
+	return func(o *bindOptions) { o.recursive = true }
+}
+
+// BindMount bind mounts the directory or file source onto target, with the
+// same restrictions and automatic unmounting as [Mount]. Use the [WithReadOnly]
+// and [WithRecursive] options to create read-only and recursive bind mounts,
+// respectively.
+//
+// BindMount is a thin wrapper around [BindMountE], failing the current test in
+// case BindMountE returns an error.
+func BindMount(source, target string, opts ...BindOption) {
+	GinkgoHelper()
+
+	unmount, err := BindMountE(source, target, opts...)
+	Expect(err).NotTo(HaveOccurred())
+	DeferCleanup(unmount)
+}
+
+// BindMountE works like [BindMount], but returns an error instead of failing
+// the current test, and an unmount function instead of scheduling a cleanup.
+func BindMountE(source, target string, opts ...BindOption) (unmount func() error, err error) {
+	var bindopts bindOptions
+	for _, opt := range opts {
+		opt(&bindopts)
+	}
+	// Unmounting a recursive bind mount with its submounts requires a lazy
+	// unmount.
+	unmountFlags := 0
+	if bindopts.recursive {
+		unmountFlags = unix.MNT_DETACH
+	}
+	return mount(target, unmountFlags, func(target string) error {
+		flags := uintptr(unix.MS_BIND)
+		if bindopts.recursive {
+			flags |= unix.MS_REC
+		}
+		if err := unix.Mount(source, target, "", flags, ""); err != nil {
+			return fmt.Errorf("cannot bind mount %s onto %s: %w", source, target, err)
+		}
+		if !bindopts.readonly {
+			return nil
+		}
+		// Bind mounts can only be made read-only by remounting them; the
+		// remount keeps the existing per-mount flags, except for the
+		// read-only flag.
+		var stat unix.Statfs_t
+		if err := unix.Statfs(target, &stat); err != nil {
+			_ = unix.Unmount(target, unix.MNT_DETACH)
+			return fmt.Errorf("cannot determine mount flags of %s: %w", target, err)
+		}
+		flags = unix.MS_BIND | unix.MS_REMOUNT | unix.MS_RDONLY |
+			uintptr(stat.Flags)&(unix.MS_NODEV|unix.MS_NOEXEC|unix.MS_NOSUID|
+				unix.MS_NOATIME|unix.MS_NODIRATIME|unix.MS_RELATIME)
+		if err := unix.Mount("", target, "", flags, ""); err != nil {
+			_ = unix.Unmount(target, unix.MNT_DETACH)
+			return fmt.Errorf("cannot make bind mount %s read-only: %w", target, err)
+		}
+		return nil
+	})
+}
+
+// mount checks that the caller isn't in the process's original mount namespace
+// and that target isn't located on a mount point shared with the host, and
+// then calls the passed mountfn with the absolute target path. It returns
+// an unmount function that unmounts target using the specified unmount flags
+// in the mount namespace the caller was in, closing its reference to this mount
+// namespace.
+func mount(target string, unmountFlags int, mountfn func(target string) error) (unmount func() error, err error) {
+	if err := notOriginal(); err != nil {
+		return nil, err
+	}
+	target, err = filepath.Abs(target)
+	if err != nil {
+		return nil, fmt.Errorf("cannot determine absolute mount target path: %w", err)
+	}
+	if err := notSharedWithOriginal(target); err != nil {
+		return nil, err
+	}
+	mntnsfd, err := unix.Open("/proc/thread-self/ns/mnt", unix.O_RDONLY|unix.O_CLOEXEC, 0)
+	if err != nil {
+		return nil, fmt.Errorf("cannot determine current mount namespace from procfs: %w", err)
+	}
+	if err := mountfn(target); err != nil {
+		_ = unix.Close(mntnsfd)
+		return nil, err
+	}
+	return func() error {
+		defer func() { _ = unix.Close(mntnsfd) }()
+		var unmountErr error
+		err := spacetest.ExecuteE(func() {
+			if err := unix.Unmount(target, unmountFlags); err != nil {
+				unmountErr = fmt.Errorf("cannot unmount %s: %w", target, err)
+			}
+		}, spacetest.Fd(mntnsfd))
+		return errors.Join(err, unmountErr)
+	}, nil
+}
+
+// notOriginal returns an error if the calling OS-level thread is still in the
+// process's original mount namespace.
+func notOriginal() error {
+	var current, original unix.Stat_t
+	if err := unix.Stat("/proc/thread-self/ns/mnt", &current); err != nil {
+		return fmt.Errorf("cannot determine current mount namespace: %w", err)
+	}
+	if err := unix.Stat("/proc/self/ns/mnt", &original); err != nil {
+		return fmt.Errorf("cannot determine process's original mount namespace: %w", err)
+	}
+	if current.Ino == original.Ino && current.Dev == original.Dev {
+		return errors.New("current mount namespace must not be the process's original mount namespace")
+	}
+	return nil
+}
+
+// notSharedWithOriginal returns an error if the mount point the specified
+// absolute path is located on in the calling OS-level thread's mount namespace
+// propagates mount events to mount points in the process's original mount
+// namespace, as mounts onto path then would propagate back into the host.
+func notSharedWithOriginal(path string) error {
+	mounts, err := spacetest.ReadMountInfoE("/proc/thread-self/mountinfo")
+	if err != nil {
+		return err
+	}
+	var parent spacetest.MountEntry
+	longest := -1
+	for _, mount := range mounts {
+		if len(mount.MountPoint) < longest || !isAtOrBelow(path, mount.MountPoint) {
+			continue
+		}
+		parent = mount
+		longest = len(mount.MountPoint)
+	}
+	if parent.PeerGroup == 0 {
+		return nil
+	}
+	originals, err := spacetest.ReadMountInfoE("/proc/self/mountinfo")
+	if err != nil {
+		return err
+	}
+	for _, original := range originals {
+		if original.PeerGroup == parent.PeerGroup || original.Master == parent.PeerGroup {
+			return fmt.Errorf("refusing to mount onto %s, as mount point %s has shared propagation with the process's original mount namespace",
+				path, parent.MountPoint)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,140 @@
+// Copyright 2025 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mntns
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	"golang.org/x/sys/unix"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gleak"
+	. "github.com/thediveo/fdooze"
+	. "github.com/thediveo/success"
+)
+
+// isMountPoint returns true if the specified path is a mount point in the
+// caller's current mount namespace.
+func isMountPoint(path string) bool {
+	var stat, parentStat unix.Stat_t
+	Expect(unix.Stat(path, &stat)).To(Succeed())
+	Expect(unix.Stat(filepath.Dir(path), &parentStat)).To(Succeed())
+	return stat.Dev != parentStat.Dev
+}
+
+var _ = Describe("mounting", func() {
+
+	BeforeEach(func() {
+		if os.Getuid() != 0 {
+			Skip("needs root")
+		}
+		goodfds := Filedescriptors()
+		goodgos := Goroutines()
+		DeferCleanup(func() {
+			Eventually(Goroutines).Within(2 * time.Second).ProbeEvery(250 * time.Millisecond).
+				ShouldNot(HaveLeaked(goodgos))
+			Expect(Filedescriptors()).NotTo(HaveLeakedFds(goodfds))
+		})
+	})
+
+	It("rejects mounting in the original mount namespace", func() {
+		scratch := GinkgoT().TempDir()
+		Expect(InterceptGomegaFailure(func() {
+			MountTmpfs(scratch)
+		})).To(MatchError(
+			ContainSubstring("current mount namespace must not be the process's original mount namespace")))
+		Expect(BindMountE(scratch, scratch)).Error().To(MatchError(
+			ContainSubstring("current mount namespace must not be the process's original mount namespace")))
+	})
+
+	It("rejects mounting onto mount points shared with the host", func() {
+		// Set up a shared scratch mount point in the host, which then gets
+		// copied into the same peer group of the transient mount namespace.
+		scratch := GinkgoT().TempDir()
+		Expect(unix.Mount("tmpfs", scratch, "tmpfs", 0, "")).To(Succeed())
+		defer func() { Expect(unix.Unmount(scratch, unix.MNT_DETACH)).To(Succeed()) }()
+		Expect(unix.Mount("none", scratch, "", unix.MS_SHARED, "")).To(Succeed())
+		Expect(os.Mkdir(filepath.Join(scratch, "sub"), 0o755)).To(Succeed())
+
+		mntnsfd, _ := NewTransient(WithSubtreePropagation(scratch, Shared))
+		Execute(mntnsfd, func() {
+			Expect(MountTmpfsE(filepath.Join(scratch, "sub"))).Error().To(MatchError(
+				ContainSubstring("has shared propagation with the process's original mount namespace")))
+			Expect(isMountPoint(filepath.Join(scratch, "sub"))).To(BeFalse())
+		})
+	})
+
+	It("mounts a tmpfs and unmounts it in the correct mount namespace", func() {
+		scratch := GinkgoT().TempDir()
+		mntnsfd, _ := NewTransient()
+		var unmount func() error
+		Execute(mntnsfd, func() {
+			unmount = Successful(MountTmpfsE(scratch))
+			Expect(isMountPoint(scratch)).To(BeTrue())
+			Expect(os.WriteFile(filepath.Join(scratch, "foo"), nil, 0o644)).To(Succeed())
+		})
+		Expect(isMountPoint(scratch)).To(BeFalse())
+		Expect(filepath.Join(scratch, "foo")).NotTo(BeAnExistingFile())
+
+		Expect(unmount()).To(Succeed())
+		Execute(mntnsfd, func() {
+			Expect(isMountPoint(scratch)).To(BeFalse())
+		})
+	})
+
+	It("bind mounts read-only and recursively, unmounting in reverse order", func() {
+		source := GinkgoT().TempDir()
+		target := GinkgoT().TempDir()
+		Expect(os.Mkdir(filepath.Join(source, "sub"), 0o755)).To(Succeed())
+
+		mntnsfd, _ := NewTransient()
+		DeferCleanup(func() {
+			// runs after the unmounts, as these get registered later.
+			Execute(mntnsfd, func() {
+				Expect(isMountPoint(target)).To(BeFalse())
+				Expect(isMountPoint(filepath.Join(source, "sub"))).To(BeFalse())
+			})
+		})
+		Execute(mntnsfd, func() {
+			MountTmpfs(filepath.Join(source, "sub"))
+			Expect(os.WriteFile(filepath.Join(source, "sub", "foo"), nil, 0o644)).To(Succeed())
+
+			BindMount(source, target, WithReadOnly(), WithRecursive())
+			Expect(filepath.Join(target, "sub", "foo")).To(BeARegularFile())
+			Expect(os.WriteFile(filepath.Join(target, "bar"), nil, 0o644)).To(
+				MatchError(unix.EROFS))
+		})
+	})
+
+	It("mounts a fresh procfs", func() {
+		scratch := GinkgoT().TempDir()
+		defer EnterTransient()()
+		MountProcfs(scratch)
+		Expect(filepath.Join(scratch, "self")).To(BeADirectory())
+	})
+
+	It("mounts generically", func() {
+		scratch := GinkgoT().TempDir()
+		defer EnterTransient()()
+		Mount("none", scratch, "tmpfs", unix.MS_RDONLY, "size=1m")
+		Expect(isMountPoint(scratch)).To(BeTrue())
+		Expect(os.WriteFile(filepath.Join(scratch, "foo"), nil, 0o644)).To(
+			MatchError(unix.EROFS))
+	})
+
+})
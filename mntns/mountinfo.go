@@ -0,0 +1,147 @@
+// Copyright 2025 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mntns
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/thediveo/spacetest"
+	"golang.org/x/sys/unix"
+
+	. "github.com/onsi/ginkgo/v2" //nolint:staticcheck // ST1001 rule does not apply
+	. "github.com/onsi/gomega"    //nolint:staticcheck // ST1001 rule does not apply
+)
+
+// MountInfo lists the mounts of a mount namespace, with parent mounts listed
+// before their child mounts.
+type MountInfo []spacetest.MountEntry
+
+// inTreeOrder returns the passed mounts ordered such that parent mounts come
+// before their child mounts, while otherwise keeping the order of sibling
+// mounts. Neither listmount(2) nor mountinfo guarantee this order, as they list
+// mounts by their IDs; for instance, after pivot_root(2) the old root mount
+// gets listed before the new root mount it is now mounted on.
+func inTreeOrder(mounts []spacetest.MountEntry) MountInfo {
+	ids := make(map[uint64]struct{}, len(mounts))
+	for _, mount := range mounts {
+		ids[mount.ID] = struct{}{}
+	}
+	var roots []int
+	children := map[uint64][]int{}
+	for idx, mount := range mounts {
+		if _, ok := ids[mount.ParentID]; !ok || mount.ParentID == mount.ID {
+			roots = append(roots, idx)
+			continue
+		}
+		children[mount.ParentID] = append(children[mount.ParentID], idx)
+	}
+	ordered := make(MountInfo, 0, len(mounts))
+	var visit func(idx int)
+	visit = func(idx int) {
+		ordered = append(ordered, mounts[idx])
+		for _, child := range children[mounts[idx].ID] {
+			visit(child)
+		}
+	}
+	for _, root := range roots {
+		visit(root)
+	}
+	return ordered
+}
+
+// Lookup returns the topmost mount at the specified mount point, and true if
+// found. Otherwise, it returns false.
+func (mi MountInfo) Lookup(mountpoint string) (spacetest.MountEntry, bool) {
+	mountpoint = filepath.Clean(mountpoint)
+	for idx := len(mi) - 1; idx >= 0; idx-- {
+		if mi[idx].MountPoint == mountpoint {
+			return mi[idx], true
+		}
+	}
+	return spacetest.MountEntry{}, false
+}
+
+// MountInfoOf returns the mounts of the mount namespace referenced by the
+// passed file descriptor or [spacetest.Namespace]. Where supported, it
+// retrieves the mounts using the [listmount(2)] and [statmount(2)] syscalls,
+// falling back to parsing the mountinfo of a thread attached to the mount
+// namespace otherwise.
+//
+// MountInfoOf is a thin wrapper around [MountInfoOfE], failing the current
+// test in case MountInfoOfE returns an error.
+//
+// [listmount(2)]: https://man7.org/linux/man-pages/man2/listmount.2.html
+// [statmount(2)]: https://man7.org/linux/man-pages/man2/statmount.2.html
+func MountInfoOf[H spacetest.Handle](mntns H) MountInfo {
+	GinkgoHelper()
+
+	mountinfo, err := MountInfoOfE(mntns)
+	Expect(err).NotTo(HaveOccurred())
+	return mountinfo
+}
+
+// MountInfoOfE works like [MountInfoOf], but returns an error instead of
+// failing the current test.
+func MountInfoOfE[H spacetest.Handle](mntns H) (MountInfo, error) {
+	if mountinfo, err := statMounts(spacetest.FdOf(mntns)); err == nil {
+		return mountinfo, nil
+	}
+	var mounts []spacetest.MountEntry
+	var readErr error
+	if err := spacetest.ExecuteE(func() {
+		mounts, readErr = spacetest.ReadMountInfoE("/proc/thread-self/mountinfo")
+	}, spacetest.Typed(mntns, unix.CLONE_NEWNS)); err != nil {
+		return nil, err
+	}
+	if readErr != nil {
+		return nil, readErr
+	}
+	return inTreeOrder(mounts), nil
+}
+
+// MountInfoAt returns the mounts of the mount namespace of the thread with the
+// specified procfsroot in the form of “/proc/$TID/root”, as returned by, for
+// instance, [NewTransient]. Same as [MountInfoOf], it prefers the
+// listmount(2) and statmount(2) syscalls, but otherwise falls back to parsing
+// the thread's mountinfo.
+//
+// MountInfoAt is a thin wrapper around [MountInfoAtE], failing the current
+// test in case MountInfoAtE returns an error.
+func MountInfoAt(procfsroot string) MountInfo {
+	GinkgoHelper()
+
+	mountinfo, err := MountInfoAtE(procfsroot)
+	Expect(err).NotTo(HaveOccurred())
+	return mountinfo
+}
+
+// MountInfoAtE works like [MountInfoAt], but returns an error instead of
+// failing the current test.
+func MountInfoAtE(procfsroot string) (MountInfo, error) {
+	mntnsfd, err := unix.Open(filepath.Join(procfsroot, "..", "ns", "mnt"), unix.O_RDONLY|unix.O_CLOEXEC, 0)
+	if err != nil {
+		return nil, fmt.Errorf("cannot open mount namespace of %s: %w", procfsroot, err)
+	}
+	defer func() { _ = unix.Close(mntnsfd) }()
+	if mountinfo, err := statMounts(mntnsfd); err == nil {
+		return mountinfo, nil
+	}
+	mounts, err := spacetest.ReadMountInfoE(filepath.Join(procfsroot, "..", "mountinfo"))
+	if err != nil {
+		return nil, err
+	}
+	return inTreeOrder(mounts), nil
+}
@@ -0,0 +1,169 @@
+// Copyright 2025 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mntns
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/onsi/gomega/gcustom"
+	"github.com/onsi/gomega/types"
+	"github.com/thediveo/spacetest"
+)
+
+// HaveMountPoint succeeds if actual has a mount at the specified mount point.
+// Actual can be a [MountInfo], a file descriptor or [spacetest.Namespace]
+// referencing a mount namespace, or a procfsroot path in the form of
+// “/proc/$TID/root”, as returned by [NewTransient]. In case of multiple mounts
+// stacked on top of each other, HaveMountPoint checks the topmost mount.
+//
+// Use [MountPointMatcher.WithFSType] and [MountPointMatcher.WithOptions] to
+// additionally check the filesystem type and mount options:
+//
+//	Expect(procfsroot).To(mntns.HaveMountPoint("/sys").
+//	    WithFSType("sysfs").WithOptions("ro"))
+func HaveMountPoint(mountpoint string) *MountPointMatcher {
+	return &MountPointMatcher{mountpoint: mountpoint}
+}
+
+// MountPointMatcher is the matcher returned by [HaveMountPoint].
+type MountPointMatcher struct {
+	mountpoint string
+	fstype     string
+	options    []string
+	actual     string // description of the actual mount, if any
+}
+
+var _ types.GomegaMatcher = (*MountPointMatcher)(nil)
+
+// WithFSType additionally requires the mount to be of the specified filesystem
+// type, such as “sysfs”.
+func (m *MountPointMatcher) WithFSType(fstype string) *MountPointMatcher {
+	m.fstype = fstype
+	return m
+}
+
+// WithOptions additionally requires the mount to have all the specified
+// options, either as per-mount options (such as “ro” and “nosuid”) or as
+// superblock options (such as “size=1024k”).
+func (m *MountPointMatcher) WithOptions(options ...string) *MountPointMatcher {
+	m.options = append(m.options, options...)
+	return m
+}
+
+// Match succeeds if actual has a matching mount at the expected mount point.
+func (m *MountPointMatcher) Match(actual any) (bool, error) {
+	mountinfo, err := mountInfoFrom(actual)
+	if err != nil {
+		return false, err
+	}
+	mount, ok := mountinfo.Lookup(m.mountpoint)
+	if !ok {
+		m.actual = "no mount at " + m.mountpoint
+		return false, nil
+	}
+	m.actual = describeMount(mount)
+	if m.fstype != "" && mount.FSType != m.fstype {
+		return false, nil
+	}
+	for _, option := range m.options {
+		if !slices.Contains(mount.Options, option) && !slices.Contains(mount.SuperOptions, option) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// FailureMessage returns the failure message for a failed positive match.
+func (m *MountPointMatcher) FailureMessage(actual any) string {
+	return fmt.Sprintf("Expected %s\nto be %s", m.actual, m.expected())
+}
+
+// NegatedFailureMessage returns the failure message for a failed negated match.
+func (m *MountPointMatcher) NegatedFailureMessage(actual any) string {
+	return fmt.Sprintf("Expected %s\nnot to be %s", m.actual, m.expected())
+}
+
+// expected returns the textual description of the expected mount.
+func (m *MountPointMatcher) expected() string {
+	var b strings.Builder
+	b.WriteString("a mount at " + m.mountpoint)
+	if m.fstype != "" {
+		b.WriteString(" of type " + m.fstype)
+	}
+	if len(m.options) != 0 {
+		b.WriteString(" with options " + strings.Join(m.options, ","))
+	}
+	return b.String()
+}
+
+// HavePropagation succeeds if the topmost mount at the specified mount point
+// of actual has the specified propagation type in its textual form, such as
+// “private”, “shared”, “slave”, “shared,slave”, or “unbindable”; see also
+// [spacetest.MountEntry.Propagation]. Actual can be the same as for [HaveMountPoint].
+func HavePropagation(mountpoint string, propagation string) types.GomegaMatcher {
+	details := &struct {
+		Actual   string
+		Expected string
+	}{Expected: propagation}
+	return gcustom.MakeMatcher(func(actual any) (bool, error) {
+		mountinfo, err := mountInfoFrom(actual)
+		if err != nil {
+			return false, err
+		}
+		mount, ok := mountinfo.Lookup(mountpoint)
+		if !ok {
+			return false, fmt.Errorf("no mount at %s", mountpoint)
+		}
+		details.Actual = describeMount(mount) + " with " + mount.Propagation() + " propagation"
+		return mount.Propagation() == propagation, nil
+	}).WithTemplate("Expected {{.Data.Actual}}\n{{.To}} have {{.Data.Expected}} propagation",
+		details)
+}
+
+// describeMount returns a textual description of the passed mount, such as
+// “sysfs mount at /sys (ro,nosuid; rw)”.
+func describeMount(mount spacetest.MountEntry) string {
+	return fmt.Sprintf("%s mount at %s (%s; %s)",
+		mount.FSType, mount.MountPoint,
+		strings.Join(mount.Options, ","), strings.Join(mount.SuperOptions, ","))
+}
+
+// mountInfoFrom returns the mounts of actual, which can be a [MountInfo], a
+// file descriptor or [spacetest.Namespace] referencing a mount namespace, or a
+// procfsroot path.
+func mountInfoFrom(actual any) (MountInfo, error) {
+	switch actual := actual.(type) {
+	case MountInfo:
+		return actual, nil
+	case *spacetest.Namespace:
+		if actual == nil {
+			return nil, errors.New("expected a mount namespace reference, got nil *Namespace")
+		}
+		return MountInfoOfE(actual)
+	case string:
+		return MountInfoAtE(actual)
+	}
+	v := reflect.ValueOf(actual)
+	if v.Kind() != reflect.Int {
+		return nil, fmt.Errorf(
+			"expected MountInfo, mount namespace reference (file descriptor or *Namespace), or procfsroot, got %T",
+			actual)
+	}
+	return MountInfoOfE(int(v.Int()))
+}
@@ -0,0 +1,178 @@
+// Copyright 2025 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mntns
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/thediveo/spacetest"
+	"github.com/thediveo/spacetest/netns"
+	"golang.org/x/sys/unix"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	. "github.com/thediveo/success"
+)
+
+var _ = Describe("mount information", func() {
+
+	Context("mount details", func() {
+
+		It("returns per-mount and superblock options", func() {
+			Expect(mountOptions(unix.MOUNT_ATTR_RDONLY | unix.MOUNT_ATTR_NOSUID | unix.MOUNT_ATTR_RELATIME)).To(
+				Equal([]string{"ro", "nosuid", "relatime"}))
+			Expect(mountOptions(unix.MOUNT_ATTR_STRICTATIME)).To(Equal([]string{"rw"}))
+			Expect(superOptions(sbRdonly|sbLazytime, `size=1k,x=a\054b`)).To(
+				Equal([]string{"ro", "lazytime", "size=1k", "x=a,b"}))
+			Expect(superOptions(0, "")).To(Equal([]string{"rw"}))
+		})
+
+		It("orders mounts as a tree", func() {
+			Expect(inTreeOrder([]spacetest.MountEntry{
+				{ID: 3, ParentID: 4, MountPoint: "/old"},
+				{ID: 5, ParentID: 3, MountPoint: "/old/proc"},
+				{ID: 4, ParentID: 1, MountPoint: "/"},
+				{ID: 6, ParentID: 4, MountPoint: "/mnt"},
+			})).To(HaveExactElements(
+				HaveField("ID", uint64(4)),
+				HaveField("ID", uint64(3)),
+				HaveField("ID", uint64(5)),
+				HaveField("ID", uint64(6))))
+		})
+
+		It("looks up the topmost mount", func() {
+			mountinfo := MountInfo{
+				{ID: 1, MountPoint: "/"},
+				{ID: 2, MountPoint: "/mnt"},
+				{ID: 3, MountPoint: "/mnt"},
+			}
+			mount, ok := mountinfo.Lookup("/mnt/")
+			Expect(ok).To(BeTrue())
+			Expect(mount.ID).To(Equal(uint64(3)))
+			_, ok = mountinfo.Lookup("/foo")
+			Expect(ok).To(BeFalse())
+		})
+
+	})
+
+	When("inspecting mount namespaces", func() {
+
+		BeforeEach(func() {
+			if os.Getuid() != 0 {
+				Skip("needs root")
+			}
+		})
+
+		It("returns the same mounts using statmount and the mountinfo fallback", func() {
+			scratch := GinkgoT().TempDir()
+			mntnsfd, procfsroot := NewTransient()
+			Execute(mntnsfd, func() {
+				Mount("none", scratch, "tmpfs", unix.MS_NOEXEC|unix.MS_NOATIME, "size=1m")
+			})
+
+			statmounted := MountInfoOf(mntnsfd)
+			Expect(statmounted).To(ContainElement(And(
+				HaveField("MountPoint", scratch),
+				HaveField("FSType", "tmpfs"),
+				HaveField("Options", ContainElements("noexec", "noatime")),
+				HaveField("SuperOptions", ContainElement("size=1024k")))))
+
+			useStatmount = false
+			DeferCleanup(func() { useStatmount = true })
+			Expect(MountInfoOf(mntnsfd)).To(Equal(statmounted))
+			Expect(MountInfoAt(procfsroot)).To(Equal(statmounted))
+		})
+
+		It("lists parent mounts before child mounts on a pivoted root", func() {
+			newroot := GinkgoT().TempDir()
+			mntnsfd, procfsroot := NewTransient()
+			Execute(mntnsfd, func() {
+				Expect(unix.Mount(newroot, newroot, "", unix.MS_BIND, "")).To(Succeed())
+				Expect(os.Mkdir(filepath.Join(newroot, "oldroot"), 0o755)).To(Succeed())
+				Expect(os.Mkdir(filepath.Join(newroot, "proc"), 0o755)).To(Succeed())
+				Expect(unix.Mount("proc", filepath.Join(newroot, "proc"), "proc", 0, "")).To(Succeed())
+				// Keep the old root, so that it gets listed before the new
+				// root mount it is now mounted on.
+				Expect(unix.PivotRoot(newroot, filepath.Join(newroot, "oldroot"))).To(Succeed())
+			})
+
+			expectTreeOrder := func(mountinfo MountInfo) {
+				GinkgoHelper()
+				positions := map[uint64]int{}
+				for idx, mount := range mountinfo {
+					positions[mount.ID] = idx
+				}
+				for idx, mount := range mountinfo {
+					if parent, ok := positions[mount.ParentID]; ok {
+						Expect(parent).To(BeNumerically("<", idx),
+							"mount %d at %s listed before its parent %d", mount.ID, mount.MountPoint, mount.ParentID)
+					}
+				}
+			}
+
+			statmounted := MountInfoOf(mntnsfd)
+			expectTreeOrder(statmounted)
+			root, ok := statmounted.Lookup("/")
+			Expect(ok).To(BeTrue())
+			Expect(statmounted).To(ContainElement(And(
+				HaveField("MountPoint", "/oldroot"),
+				HaveField("ParentID", root.ID))))
+
+			useStatmount = false
+			DeferCleanup(func() { useStatmount = true })
+			Expect(MountInfoOf(mntnsfd)).To(Equal(statmounted))
+			Expect(MountInfoAt(procfsroot)).To(Equal(statmounted))
+		})
+
+		It("matches mount points", func() {
+			defer netns.EnterTransient()()
+			mntnsfd, procfsroot := NewTransient()
+			Execute(mntnsfd, func() {
+				MountSysfsRO()
+			})
+
+			Expect(procfsroot).To(HaveMountPoint("/sys").WithFSType("sysfs").WithOptions("ro", "nosuid"))
+			Expect(mntnsfd).To(HaveMountPoint("/sys"))
+			Expect(MountInfoAt(procfsroot)).NotTo(HaveMountPoint("/sys").WithFSType("proc"))
+			Expect(procfsroot).NotTo(HaveMountPoint("/sys").WithOptions("rw"))
+			Expect(procfsroot).NotTo(HaveMountPoint("/nonexisting"))
+			Expect(procfsroot).To(HavePropagation("/", "private"))
+			Expect(mntnsfd).NotTo(HavePropagation("/sys", Shared.String()))
+
+			Expect(HaveMountPoint("/sys").WithFSType("proc").Match(mntnsfd)).To(BeFalse())
+			Expect(InterceptGomegaFailure(func() {
+				Expect(mntnsfd).To(HaveMountPoint("/sys").WithFSType("proc"))
+			})).To(MatchError(And(
+				ContainSubstring("Expected sysfs mount at /sys (ro,"),
+				ContainSubstring("to be a mount at /sys of type proc"))))
+			Expect(InterceptGomegaFailure(func() {
+				Expect(mntnsfd).To(HavePropagation("/", "shared"))
+			})).To(MatchError(ContainSubstring("to have shared propagation")))
+		})
+
+		It("rejects invalid actual values", func() {
+			Expect(HaveMountPoint("/").Match(42.0)).Error().To(
+				MatchError(ContainSubstring("got float64")))
+			Expect(HavePropagation("/", "private").Match(MountInfo{})).Error().To(
+				MatchError(ContainSubstring("no mount at /")))
+			Expect(HaveMountPoint("/").Match(filepath.Join("/nonexisting", "root"))).Error().To(
+				HaveOccurred())
+			Expect(Successful(HaveMountPoint("/").Match(MountInfo{{MountPoint: "/"}}))).To(BeTrue())
+		})
+
+	})
+
+})
@@ -0,0 +1,149 @@
+// Copyright 2025 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mntns
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/thediveo/spacetest"
+	"golang.org/x/sys/unix"
+
+	. "github.com/onsi/ginkgo/v2" //nolint:staticcheck // ST1001 rule does not apply
+	. "github.com/onsi/gomega"    //nolint:staticcheck // ST1001 rule does not apply
+)
+
+// NewOverlayRoot creates a new transient mount namespace, similar to
+// [NewTransient], but additionally puts an overlay filesystem over “/” inside
+// this mount namespace. Tests then can write anywhere into the filesystem, such
+// as to “/etc” and “/var/lib”, without touching the host. All writes go into a
+// tmpfs-based upper directory and thus vanish when the mount namespace goes
+// away.
+//
+// NewOverlayRoot returns a file descriptor referencing the new mount namespace,
+// the “procfsroot” path in the form of “/proc/$TID/root” to access the
+// overlay'ed filesystem from outside the mount namespace, and the path of the
+// upper directory inside the mount namespace. From outside the mount
+// namespace, the upper directory can be accessed as procfsroot+upperdir in
+// order to check what a test has written.
+//
+// As overlay filesystems only cover the root filesystem itself, NewOverlayRoot
+// recursively bind mounts all other mounts directly below “/”, such as “/proc”,
+// “/sys”, and “/dev”, into the overlay'ed root; writes to these mounts thus are
+// not caught by the overlay.
+//
+// NewOverlayRoot is a thin wrapper around [NewOverlayRootE], failing the
+// current test in case NewOverlayRootE returns an error.
+func NewOverlayRoot() (mntfd int, procfsroot string, upperdir string) {
+	GinkgoHelper()
+
+	mntfd, procfsroot, upperdir, release, err := NewOverlayRootE()
+	Expect(err).NotTo(HaveOccurred())
+	DeferCleanup(release)
+	return mntfd, procfsroot, upperdir
+}
+
+// NewOverlayRootE works like [NewOverlayRoot], but instead of failing the
+// current test it returns an error in case the new mount namespace cannot be
+// created. NewOverlayRootE thus can also be used outside Ginkgo tests.
+//
+// Instead of scheduling any cleanup, NewOverlayRootE returns a release function
+// that the caller must call in order to close the returned file descriptor,
+// to terminate the idle OS-level thread, and to remove the (empty) mount point
+// directory of the tmpfs holding the upper directory.
+func NewOverlayRootE() (mntfd int, procfsroot string, upperdir string, release func(), err error) {
+	scratch, err := os.MkdirTemp("", "spacetest-overlay-")
+	if err != nil {
+		return -1, "", "", nil, fmt.Errorf("cannot create overlay scratch directory: %w", err)
+	}
+
+	mntns, procfsroot, releaseIdler, err := newIdler(newOptions(), func() error { return overlayRoot(scratch) })
+	if err != nil {
+		_ = os.Remove(scratch)
+		return -1, "", "", nil, err
+	}
+	return mntns.Fd(), procfsroot, filepath.Join(scratch, "upper"), func() {
+		releaseIdler()
+		_ = os.Remove(scratch)
+	}, nil
+}
+
+// overlayRoot mounts a tmpfs onto the scratch directory, with the upper and
+// work directories of an overlay filesystem over “/” that gets mounted onto
+// “merged” inside the scratch directory. It then recursively bind mounts all
+// mounts directly below “/” into the overlay and finally makes the overlay the
+// new root of the caller's (new) mount namespace.
+func overlayRoot(scratch string) error {
+	if err := unix.Mount("tmpfs", scratch, "tmpfs",
+		unix.MS_NODEV|unix.MS_NOSUID|unix.MS_RELATIME, "mode=0700"); err != nil {
+		return fmt.Errorf("cannot mount tmpfs on %s: %w", scratch, err)
+	}
+	upper := filepath.Join(scratch, "upper")
+	work := filepath.Join(scratch, "work")
+	merged := filepath.Join(scratch, "merged")
+	for _, dir := range []string{upper, work, merged} {
+		if err := os.Mkdir(dir, 0o755); err != nil {
+			return fmt.Errorf("cannot create overlay directory: %w", err)
+		}
+	}
+	if err := unix.Mount("overlay", merged, "overlay", 0,
+		"lowerdir=/,upperdir="+upper+",workdir="+work); err != nil {
+		return fmt.Errorf("cannot mount overlay on %s: %w", merged, err)
+	}
+
+	mountpoints, err := rootChildMounts()
+	if err != nil {
+		return err
+	}
+	for _, mountpoint := range mountpoints {
+		if mountpoint == scratch {
+			// Make the scratch tmpfs with the upper directory available at
+			// the same path inside the overlay'ed root.
+			if err := unix.Mount(scratch, filepath.Join(merged, scratch), "", unix.MS_BIND, ""); err != nil {
+				return fmt.Errorf("cannot bind mount %s into overlay: %w", scratch, err)
+			}
+			continue
+		}
+		if err := unix.Mount(mountpoint, filepath.Join(merged, mountpoint), "",
+			unix.MS_BIND|unix.MS_REC, ""); err != nil {
+			return fmt.Errorf("cannot bind mount %s into overlay: %w", mountpoint, err)
+		}
+	}
+
+	return pivotRoot(merged)
+}
+
+// rootChildMounts returns the mount points of the mounts directly below the
+// root mount of the calling thread's mount namespace, in the order of the
+// thread's mountinfo.
+func rootChildMounts() ([]string, error) {
+	mounts, err := spacetest.ReadMountInfoE("/proc/thread-self/mountinfo")
+	if err != nil {
+		return nil, err
+	}
+	root, ok := MountInfo(mounts).Lookup("/")
+	if !ok {
+		return nil, errors.New("no root mount")
+	}
+	var mountpoints []string
+	for _, m := range mounts {
+		if m.ParentID == root.ID && m.ID != root.ID {
+			mountpoints = append(mountpoints, m.MountPoint)
+		}
+	}
+	return mountpoints, nil
+}
@@ -0,0 +1,74 @@
+// Copyright 2025 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mntns
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gleak"
+	. "github.com/thediveo/fdooze"
+	. "github.com/thediveo/success"
+)
+
+var _ = Describe("overlay'ed root", func() {
+
+	BeforeEach(func() {
+		if os.Getuid() != 0 {
+			Skip("needs root")
+		}
+		goodfds := Filedescriptors()
+		goodgos := Goroutines()
+		DeferCleanup(func() {
+			Eventually(Goroutines).Within(2 * time.Second).ProbeEvery(250 * time.Millisecond).
+				ShouldNot(HaveLeaked(goodgos))
+			Expect(Filedescriptors()).NotTo(HaveLeakedFds(goodfds))
+		})
+	})
+
+	It("writes anywhere without touching the host", func() {
+		const canary = "/etc/spacetest-overlay-canary"
+		Expect(canary).NotTo(BeAnExistingFile())
+
+		mntnsfd, procfsroot, upperdir := NewOverlayRoot()
+		Expect(mntnsfd).NotTo(BeZero())
+		Expect(procfsroot).NotTo(BeEmpty())
+		Expect(upperdir).NotTo(BeEmpty())
+
+		Execute(mntnsfd, func() {
+			Expect(os.WriteFile(canary, []byte("tweet"), 0o644)).To(Succeed())
+			Expect(filepath.Join(upperdir, canary)).To(BeARegularFile())
+			Expect("/proc/self/ns/mnt").To(BeAnExistingFile())
+			Expect(len(Successful(os.ReadDir("/dev")))).To(BeNumerically(">", 1))
+		})
+		Expect(canary).NotTo(BeAnExistingFile())
+		Expect(os.ReadFile(filepath.Join(procfsroot, canary))).To(Equal([]byte("tweet")))
+		Expect(os.ReadFile(filepath.Join(procfsroot, upperdir, canary))).To(Equal([]byte("tweet")))
+	})
+
+	It("releases the overlay'ed root", func() {
+		mntnsfd, _, upperdir, release, err := NewOverlayRootE()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(mntnsfd).NotTo(BeZero())
+		scratch := filepath.Dir(upperdir)
+		Expect(scratch).To(BeADirectory())
+		release()
+		Expect(scratch).NotTo(BeAnExistingFile())
+	})
+
+})
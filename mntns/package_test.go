@@ -17,10 +17,14 @@ package mntns
 import (
 	"testing"
 
+	"github.com/thediveo/spacetest"
+
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 )
 
+var _ = spacetest.AuditThreadNamespaces()
+
 func TestMntns(t *testing.T) {
 	RegisterFailHandler(Fail)
 	RunSpecs(t, "spacetest/mntns package")
@@ -0,0 +1,169 @@
+// Copyright 2025 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mntns
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/thediveo/spacetest"
+	"golang.org/x/sys/unix"
+)
+
+// Propagation specifies the propagation type of mount points, see also
+// [mount_namespaces(7)].
+//
+// [mount_namespaces(7)]: https://man7.org/linux/man-pages/man7/mount_namespaces.7.html
+type Propagation uintptr
+
+// Mount point propagation types.
+const (
+	// Private mount points neither propagate events to nor receive events
+	// from other mount points. This is the default for transient mount
+	// namespaces.
+	Private Propagation = unix.MS_PRIVATE
+	// Slave mount points receive propagation events from their master peer
+	// group, such as the host's mount points, but don't propagate events back.
+	Slave Propagation = unix.MS_SLAVE
+	// Shared mount points propagate events to and receive events from the
+	// other members of their peer group. Please be careful, as this might
+	// propagate mounts back into the host.
+	Shared Propagation = unix.MS_SHARED
+	// Unbindable mount points are private and additionally cannot be bind
+	// mounted.
+	Unbindable Propagation = unix.MS_UNBINDABLE
+)
+
+// String returns the name of the propagation type, such as “private”.
+func (p Propagation) String() string {
+	switch p {
+	case Private:
+		return "private"
+	case Slave:
+		return "slave"
+	case Shared:
+		return "shared"
+	case Unbindable:
+		return "unbindable"
+	}
+	return fmt.Sprintf("Propagation(%#x)", uintptr(p))
+}
+
+// Option configures a transient mount namespace created by, for instance,
+// [EnterTransient] and [NewTransient].
+type Option func(*options)
+
+type options struct {
+	propagation Propagation
+	subtrees    []subtreePropagation
+}
+
+type subtreePropagation struct {
+	path        string
+	propagation Propagation
+}
+
+// newOptions returns the options resulting from applying the passed options to
+// the default options.
+func newOptions(opts ...Option) options {
+	o := options{propagation: Private}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// WithPropagation sets the propagation type of all mount points in the new
+// transient mount namespace, instead of the safe default [Private].
+func WithPropagation(prop Propagation) Option {
+	return func(o *options) { o.propagation = prop }
+}
+
+// WithSubtreePropagation sets the propagation type of the mount points at or
+// below the specified path in the new transient mount namespace, overriding
+// the propagation type of the whole mount point tree. When specifying
+// overlapping subtrees, the most specific subtree wins.
+func WithSubtreePropagation(path string, prop Propagation) Option {
+	return func(o *options) {
+		o.subtrees = append(o.subtrees, subtreePropagation{
+			path:        filepath.Clean(path),
+			propagation: prop,
+		})
+	}
+}
+
+// ForTransientSet returns a [spacetest.SetOption] that applies the mount point
+// propagation specified by the passed options to the new mount namespace of a
+// set of transient namespaces, such as created by [spacetest.NewTransientSet]
+// and [spacetest.EnterTransientSet]. For instance:
+//
+//	set := spacetest.NewTransientSet(unix.CLONE_NEWNS|unix.CLONE_NEWNET,
+//	    mntns.ForTransientSet(mntns.WithPropagation(mntns.Slave)))
+func ForTransientSet(opts ...Option) spacetest.SetOption {
+	o := newOptions(opts...)
+	return spacetest.WithMountSetup(func() error { return applyPropagation(o) })
+}
+
+// applyPropagation changes the propagation type of the mount points in the
+// calling thread's mount namespace according to the passed options.
+//
+// Without any subtrees, it simply recursively remounts “/”. Otherwise, it needs
+// to change the propagation type of each mount point individually, as changing
+// the propagation type of “/” recursively first would already break the peer
+// relations required for, say, slave subtrees.
+func applyPropagation(o options) error {
+	if len(o.subtrees) == 0 {
+		if err := unix.Mount("none", "/", "", unix.MS_REC|uintptr(o.propagation), ""); err != nil {
+			return fmt.Errorf("cannot change / mount propagation to %s: %w", o.propagation, err)
+		}
+		return nil
+	}
+	mounts, err := spacetest.ReadMountInfoE("/proc/thread-self/mountinfo")
+	if err != nil {
+		return err
+	}
+	for _, mount := range mounts {
+		prop := o.propagationOf(mount.MountPoint)
+		if err := unix.Mount("none", mount.MountPoint, "", uintptr(prop), ""); err != nil {
+			return fmt.Errorf("cannot change %s mount propagation to %s: %w",
+				mount.MountPoint, prop, err)
+		}
+	}
+	return nil
+}
+
+// propagationOf returns the propagation type for the specified mount point,
+// taking subtrees into account.
+func (o options) propagationOf(mountpoint string) Propagation {
+	prop := o.propagation
+	longest := -1
+	for _, subtree := range o.subtrees {
+		if len(subtree.path) < longest || !isAtOrBelow(mountpoint, subtree.path) {
+			continue
+		}
+		prop = subtree.propagation
+		longest = len(subtree.path)
+	}
+	return prop
+}
+
+// isAtOrBelow returns true if path is the same as dir or is located below dir.
+func isAtOrBelow(path, dir string) bool {
+	if dir == "/" || path == dir {
+		return true
+	}
+	return strings.HasPrefix(path, dir+"/")
+}
@@ -0,0 +1,163 @@
+// Copyright 2025 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mntns
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/thediveo/spacetest"
+	"golang.org/x/sys/unix"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	. "github.com/thediveo/success"
+)
+
+// propagationTags returns the optional propagation fields, such as “shared:1”
+// and “master:2”, of the topmost mount at the specified mount point of the
+// calling thread's mount namespace.
+func propagationTags(mountpoint string) []string {
+	GinkgoHelper()
+
+	var tags []string
+	found := false
+	mountinfo := string(Successful(os.ReadFile("/proc/thread-self/mountinfo")))
+	for line := range strings.Lines(mountinfo) {
+		fields, _, _ := strings.Cut(line, " - ")
+		mountfields := strings.Fields(fields)
+		if len(mountfields) < 6 || spacetest.UnescapeOctal(mountfields[4]) != mountpoint {
+			continue
+		}
+		found = true
+		tags = []string{}
+		for _, field := range mountfields[6:] {
+			tags = append(tags, strings.Split(field, ":")[0])
+		}
+	}
+	Expect(found).To(BeTrue(), "no mount point %s", mountpoint)
+	return tags
+}
+
+var _ = Describe("mount point propagation", func() {
+
+	BeforeEach(func() {
+		if os.Getuid() != 0 {
+			Skip("needs root")
+		}
+	})
+
+	It("returns propagation type names", func() {
+		Expect(Private.String()).To(Equal("private"))
+		Expect(Slave.String()).To(Equal("slave"))
+		Expect(Shared.String()).To(Equal("shared"))
+		Expect(Unbindable.String()).To(Equal("unbindable"))
+		Expect(Propagation(0).String()).To(Equal("Propagation(0x0)"))
+	})
+
+	It("picks the most specific subtree", func() {
+		o := newOptions(
+			WithPropagation(Shared),
+			WithSubtreePropagation("/foo/bar/", Unbindable),
+			WithSubtreePropagation("/foo", Slave))
+		Expect(o.propagationOf("/")).To(Equal(Shared))
+		Expect(o.propagationOf("/foobar")).To(Equal(Shared))
+		Expect(o.propagationOf("/foo")).To(Equal(Slave))
+		Expect(o.propagationOf("/foo/baz")).To(Equal(Slave))
+		Expect(o.propagationOf("/foo/bar")).To(Equal(Unbindable))
+		Expect(o.propagationOf("/foo/bar/baz")).To(Equal(Unbindable))
+	})
+
+	It("defaults to private", func() {
+		mntnsfd, _ := NewTransient()
+		Execute(mntnsfd, func() {
+			Expect(propagationTags("/")).To(BeEmpty())
+		})
+	})
+
+	It("sets the propagation of the whole tree", func() {
+		mntnsfd, _ := NewTransient(WithPropagation(Unbindable))
+		Execute(mntnsfd, func() {
+			Expect(propagationTags("/")).To(ConsistOf("unbindable"))
+			Expect(propagationTags("/proc")).To(ConsistOf("unbindable"))
+		})
+	})
+
+	It("sets the propagation in a transient namespace set", func() {
+		set := spacetest.NewTransientSet(unix.CLONE_NEWNS|unix.CLONE_NEWNET,
+			ForTransientSet(WithPropagation(Unbindable)))
+		Execute(set.Mnt, func() {
+			Expect(propagationTags("/")).To(ConsistOf("unbindable"))
+		})
+	})
+
+	DescribeTable("receiving mounts from the parent mount namespace",
+		func(shouldReceive bool, opts func(scratch string) []Option) {
+			runtime.LockOSThread() // EnterTransient never unlocks anyway.
+			defer runtime.UnlockOSThread()
+
+			// Set up an outer mount namespace with a shared scratch mount
+			// point that will propagate mounts into inner slaves.
+			scratch := GinkgoT().TempDir()
+			defer EnterTransient()()
+			MountTmpfs(scratch)
+			Expect(unix.Mount("none", scratch, "", unix.MS_SHARED, "")).To(Succeed())
+			Expect(os.Mkdir(filepath.Join(scratch, "sub"), 0o755)).To(Succeed())
+			outer := Current()
+
+			defer EnterTransient(opts(scratch)...)()
+			Expect(propagationTags("/")).To(BeEmpty())
+
+			Execute(outer, func() {
+				MountTmpfs(filepath.Join(scratch, "sub"))
+			})
+			Expect(isMountPoint(filepath.Join(scratch, "sub"))).To(Equal(shouldReceive))
+		},
+		Entry("private", false, func(string) []Option { return nil }),
+		Entry("slave subtree", true, func(scratch string) []Option {
+			return []Option{WithSubtreePropagation(scratch, Slave)}
+		}),
+		Entry("private subtree of slave tree", false, func(scratch string) []Option {
+			return []Option{
+				WithPropagation(Slave),
+				WithSubtreePropagation(scratch, Private),
+				WithSubtreePropagation("/", Private),
+			}
+		}),
+	)
+
+	It("doesn't propagate slave mounts back", func() {
+		runtime.LockOSThread()
+		defer runtime.UnlockOSThread()
+
+		scratch := GinkgoT().TempDir()
+		defer EnterTransient()()
+		MountTmpfs(scratch)
+		Expect(unix.Mount("none", scratch, "", unix.MS_SHARED, "")).To(Succeed())
+		Expect(os.Mkdir(filepath.Join(scratch, "sub"), 0o755)).To(Succeed())
+
+		outer := Current()
+
+		defer EnterTransient(WithSubtreePropagation(scratch, Slave))()
+		Expect(propagationTags(scratch)).To(ConsistOf("master"))
+		MountTmpfs(filepath.Join(scratch, "sub"))
+		Execute(outer, func() {
+			Expect(isMountPoint(filepath.Join(scratch, "sub"))).To(BeFalse())
+		})
+	})
+
+})
@@ -0,0 +1,160 @@
+// Copyright 2025 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mntns
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"golang.org/x/sys/unix"
+
+	. "github.com/onsi/ginkgo/v2" //nolint:staticcheck // ST1001 rule does not apply
+	. "github.com/onsi/gomega"    //nolint:staticcheck // ST1001 rule does not apply
+)
+
+// sandboxDevices lists the device files that get bind mounted from the host's
+// “/dev” into the minimal “/dev” of a sandbox.
+var sandboxDevices = []string{"null", "zero", "random", "urandom", "tty"}
+
+// NewSandboxRoot creates a new transient mount namespace with the directory
+// tree dir as its new root, using [pivot_root(2)]. Inside the new root, it
+// mounts fresh “/proc” and (read-only) “/sys” instances, as well as a minimal
+// tmpfs-based “/dev” with only the “null”, “zero”, “random”, “urandom”, and
+// “tty” devices bind mounted from the host. Missing “proc”, “sys”, and “dev”
+// directories get created in dir.
+//
+// Same as [NewTransient], the new mount namespace is kept alive by an idle
+// OS-level thread, so the caller's OS-level thread is never affected. The idle
+// thread is automatically terminated and the returned file descriptor closed
+// upon returning from the current test. Use [Execute] with the returned file
+// descriptor to run functions inside the sandbox, or [ExecuteInSandbox].
+//
+// NewSandboxRoot is a thin wrapper around [NewSandboxRootE], failing the
+// current test in case NewSandboxRootE returns an error.
+//
+// [pivot_root(2)]: https://man7.org/linux/man-pages/man2/pivot_root.2.html
+func NewSandboxRoot(dir string) (mntfd int, procfsroot string) {
+	GinkgoHelper()
+
+	mntfd, procfsroot, release, err := NewSandboxRootE(dir)
+	Expect(err).NotTo(HaveOccurred())
+	DeferCleanup(release)
+	return mntfd, procfsroot
+}
+
+// NewSandboxRootE works like [NewSandboxRoot], but instead of failing the
+// current test it returns an error in case the sandbox cannot be created.
+// NewSandboxRootE thus can also be used outside Ginkgo tests.
+//
+// Instead of scheduling any cleanup, NewSandboxRootE returns a release function
+// that the caller must call in order to close the returned file descriptor and
+// to terminate the idle OS-level thread.
+func NewSandboxRootE(dir string) (mntfd int, procfsroot string, release func(), err error) {
+	dir, err = filepath.Abs(dir)
+	if err != nil {
+		return -1, "", nil, fmt.Errorf("cannot determine absolute sandbox root path: %w", err)
+	}
+	mntns, procfsroot, release, err := newIdler(newOptions(), func() error { return sandboxRoot(dir) })
+	if err != nil {
+		return -1, "", nil, err
+	}
+	return mntns.Fd(), procfsroot, release, nil
+}
+
+// ExecuteInSandbox creates a new sandbox with the directory tree dir as its
+// root, as described in [NewSandboxRoot], and synchronously executes fn inside
+// it. The sandbox gets automatically removed when ExecuteInSandbox returns.
+//
+// ExecuteInSandbox is a thin wrapper around [ExecuteInSandboxE], failing the
+// current test in case ExecuteInSandboxE returns an error.
+func ExecuteInSandbox(dir string, fn func()) {
+	GinkgoHelper()
+
+	Expect(ExecuteInSandboxE(dir, fn)).To(Succeed())
+}
+
+// ExecuteInSandboxE works like [ExecuteInSandbox], but returns an error instead
+// of failing the current test in case the sandbox cannot be created or
+// entered.
+func ExecuteInSandboxE(dir string, fn func()) error {
+	mntfd, _, release, err := NewSandboxRootE(dir)
+	if err != nil {
+		return err
+	}
+	defer release()
+	return ExecuteE(mntfd, fn)
+}
+
+// sandboxRoot turns dir into the new root of the calling thread's mount
+// namespace, with fresh procfs and sysfs instances, as well as a minimal
+// “/dev”.
+func sandboxRoot(dir string) error {
+	// pivot_root(2) requires the new root to be a mount point.
+	if err := unix.Mount(dir, dir, "", unix.MS_BIND|unix.MS_REC, ""); err != nil {
+		return fmt.Errorf("cannot bind mount sandbox root %s: %w", dir, err)
+	}
+	for _, subdir := range []string{"proc", "sys", "dev"} {
+		if err := os.MkdirAll(filepath.Join(dir, subdir), 0o755); err != nil {
+			return fmt.Errorf("cannot create sandbox mount point: %w", err)
+		}
+	}
+	if err := unix.Mount("proc", filepath.Join(dir, "proc"), "proc",
+		unix.MS_NODEV|unix.MS_NOEXEC|unix.MS_NOSUID|unix.MS_RELATIME, ""); err != nil {
+		return fmt.Errorf("cannot mount sandbox /proc: %w", err)
+	}
+	if err := unix.Mount("sysfs", filepath.Join(dir, "sys"), "sysfs",
+		unix.MS_RDONLY|unix.MS_NODEV|unix.MS_NOEXEC|unix.MS_NOSUID|unix.MS_RELATIME, ""); err != nil {
+		return fmt.Errorf("cannot mount sandbox /sys: %w", err)
+	}
+	dev := filepath.Join(dir, "dev")
+	if err := unix.Mount("tmpfs", dev, "tmpfs",
+		unix.MS_NOEXEC|unix.MS_NOSUID|unix.MS_RELATIME, "mode=0755"); err != nil {
+		return fmt.Errorf("cannot mount sandbox /dev: %w", err)
+	}
+	// Instead of creating device nodes, bind mount the host's device files,
+	// as this also works when we lack CAP_MKNOD.
+	for _, device := range sandboxDevices {
+		target := filepath.Join(dev, device)
+		if err := os.WriteFile(target, nil, 0o666); err != nil {
+			return fmt.Errorf("cannot create sandbox device mount point: %w", err)
+		}
+		if err := unix.Mount(filepath.Join("/dev", device), target, "", unix.MS_BIND, ""); err != nil {
+			return fmt.Errorf("cannot bind mount device %s into sandbox: %w", device, err)
+		}
+	}
+	return pivotRoot(dir)
+}
+
+// pivotRoot makes newroot the new root of the calling thread's mount
+// namespace, detaching the old root, and then changes into the new root.
+func pivotRoot(newroot string) error {
+	// Swap the root mount of our mount namespace for the new root, stacking
+	// the old root on top of the new root, then detach the old root. See also
+	// pivot_root(2) on “pivot_root(".", ".")”.
+	if err := unix.Chdir(newroot); err != nil {
+		return fmt.Errorf("cannot change into %s: %w", newroot, err)
+	}
+	if err := unix.PivotRoot(".", "."); err != nil {
+		return fmt.Errorf("cannot pivot root to %s: %w", newroot, err)
+	}
+	if err := unix.Unmount(".", unix.MNT_DETACH); err != nil {
+		return fmt.Errorf("cannot detach old root: %w", err)
+	}
+	if err := unix.Chdir("/"); err != nil {
+		return fmt.Errorf("cannot change into new root: %w", err)
+	}
+	return nil
+}
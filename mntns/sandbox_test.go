@@ -0,0 +1,89 @@
+// Copyright 2025 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mntns
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gleak"
+	. "github.com/thediveo/fdooze"
+	. "github.com/thediveo/success"
+)
+
+var _ = Describe("sandboxes", func() {
+
+	BeforeEach(func() {
+		if os.Getuid() != 0 {
+			Skip("needs root")
+		}
+		goodfds := Filedescriptors()
+		goodgos := Goroutines()
+		DeferCleanup(func() {
+			Eventually(Goroutines).Within(2 * time.Second).ProbeEvery(250 * time.Millisecond).
+				ShouldNot(HaveLeaked(goodgos))
+			Expect(Filedescriptors()).NotTo(HaveLeakedFds(goodfds))
+		})
+	})
+
+	It("pivots into a sandbox root", func() {
+		dir := GinkgoT().TempDir()
+		Expect(os.WriteFile(filepath.Join(dir, "hello"), []byte("world"), 0o644)).To(Succeed())
+
+		mntfd, procfsroot := NewSandboxRoot(dir)
+		Expect(os.ReadFile(filepath.Join(procfsroot, "hello"))).To(Equal([]byte("world")))
+
+		Execute(mntfd, func() {
+			Expect(os.ReadFile("/hello")).To(Equal([]byte("world")))
+			Expect("/etc").NotTo(BeAnExistingFile())
+			Expect("/proc/self/ns/mnt").To(BeAnExistingFile())
+			Expect("/sys/kernel").To(BeADirectory())
+			Expect(Successful(os.ReadDir("/dev"))).To(ConsistOf(
+				HaveField("Name()", "null"),
+				HaveField("Name()", "zero"),
+				HaveField("Name()", "random"),
+				HaveField("Name()", "urandom"),
+				HaveField("Name()", "tty"),
+			))
+			Expect(os.WriteFile("/dev/null", []byte("void"), 0)).To(Succeed())
+		})
+		Expect(filepath.Join(dir, "hello")).To(BeARegularFile())
+	})
+
+	It("executes in a sandbox", func() {
+		dir := GinkgoT().TempDir()
+		Expect(os.Mkdir(filepath.Join(dir, "empty"), 0o755)).To(Succeed())
+		count := 0
+		ExecuteInSandbox(dir, func() {
+			count++
+			Expect(Successful(os.ReadDir("/"))).To(ConsistOf(
+				HaveField("Name()", "empty"),
+				HaveField("Name()", "proc"),
+				HaveField("Name()", "sys"),
+				HaveField("Name()", "dev"),
+			))
+		})
+		Expect(count).To(Equal(1), "didn't call fn")
+	})
+
+	It("reports failing to create a sandbox", func() {
+		Expect(ExecuteInSandboxE("/nonexisting-sandbox-root", func() {})).To(
+			MatchError(ContainSubstring("cannot bind mount sandbox root")))
+	})
+
+})
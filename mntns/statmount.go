@@ -0,0 +1,280 @@
+// Copyright 2025 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mntns
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strings"
+	"unsafe"
+
+	"github.com/thediveo/spacetest"
+	"golang.org/x/sys/unix"
+)
+
+// NS_GET_MNTNS_ID defines the ioctl request code returning the 64-bit ID of
+// the mount namespace referenced by a file descriptor (since Linux 6.8); see
+// also [nsfs.h].
+//
+// [nsfs.h]: https://elixir.bootlin.com/linux/v6.11/source/include/uapi/linux/nsfs.h
+const NS_GET_MNTNS_ID = 0x8008b705 // _IOR(0xb7, 0x5, __u64)
+
+// useStatmount can be switched off by unit tests in order to test the mountinfo
+// fallback on kernels supporting listmount(2) and statmount(2).
+var useStatmount = true
+
+// lsmtRoot requests listmount(2) to list all mounts of a mount namespace.
+const lsmtRoot = ^uint64(0)
+
+// statmount(2) request mask bits, see also [mount.h].
+//
+// [mount.h]: https://elixir.bootlin.com/linux/v6.13/source/include/uapi/linux/mount.h
+const (
+	statmountSBBasic   = 0x00000001
+	statmountMntBasic  = 0x00000002
+	statmountMntRoot   = 0x00000008
+	statmountMntPoint  = 0x00000010
+	statmountFSType    = 0x00000020
+	statmountMntOpts   = 0x00000080
+	statmountFSSubtype = 0x00000100
+	statmountSBSource  = 0x00000200
+)
+
+// mntIDReq is the request passed to listmount(2) and statmount(2), in its
+// MNT_ID_REQ_SIZE_VER1 variant supporting mount namespace IDs (since Linux
+// 6.11).
+type mntIDReq struct {
+	size    uint32
+	spare   uint32
+	mntID   uint64
+	param   uint64
+	mntNsID uint64
+}
+
+// Offsets of the fields of the struct statmount returned by statmount(2); the
+// variable-sized string area starts after the fixed-size part.
+const (
+	smMntOpts      = 4
+	smMask         = 8
+	smSBDevMajor   = 16
+	smSBDevMinor   = 20
+	smSBFlags      = 32
+	smFSType       = 36
+	smMntIDOld     = 56
+	smParentIDOld  = 60
+	smMntAttr      = 64
+	smPropagation  = 72
+	smPeerGroup    = 80
+	smMaster       = 88
+	smMntRoot      = 104
+	smMntPoint     = 108
+	smFSSubtype    = 120
+	smSBSource     = 124
+	smStringsStart = 512
+)
+
+// Superblock flags as returned by statmount(2).
+const (
+	sbRdonly      = 0x00000001
+	sbSynchronous = 0x00000010
+	sbDirsync     = 0x00000080
+	sbLazytime    = 0x02000000
+)
+
+// statMounts returns the mounts of the mount namespace referenced by the
+// passed file descriptor using listmount(2) and statmount(2), with parent
+// mounts listed before their child mounts. It returns an
+// error if the kernel doesn't support these syscalls or doesn't support
+// querying mount namespaces by their IDs.
+func statMounts(mntnsfd int) (MountInfo, error) {
+	if !useStatmount {
+		return nil, errors.New("listmount and statmount disabled")
+	}
+	var nsid uint64
+	if _, _, errno := unix.Syscall(unix.SYS_IOCTL,
+		uintptr(mntnsfd), NS_GET_MNTNS_ID, uintptr(unsafe.Pointer(&nsid))); errno != 0 {
+		return nil, fmt.Errorf("cannot determine mount namespace ID: %w", errno)
+	}
+	ids, err := listMounts(nsid)
+	if err != nil {
+		return nil, err
+	}
+	mounts := make([]spacetest.MountEntry, 0, len(ids))
+	buf := make([]byte, 4096)
+	for _, id := range ids {
+		var mount spacetest.MountEntry
+		mount, buf, err = statMount(nsid, id, buf)
+		if errors.Is(err, unix.ENOENT) {
+			continue // mount has vanished in the meantime.
+		}
+		if err != nil {
+			return nil, err
+		}
+		mounts = append(mounts, mount)
+	}
+	return inTreeOrder(mounts), nil
+}
+
+// listMounts returns the (unique) IDs of all mounts of the mount namespace
+// with the specified ID.
+func listMounts(nsid uint64) ([]uint64, error) {
+	var ids []uint64
+	batch := make([]uint64, 256)
+	last := uint64(0)
+	for {
+		req := mntIDReq{
+			size:    unix.MNT_ID_REQ_SIZE_VER1,
+			mntID:   lsmtRoot,
+			param:   last,
+			mntNsID: nsid,
+		}
+		n, _, errno := unix.Syscall6(unix.SYS_LISTMOUNT,
+			uintptr(unsafe.Pointer(&req)),
+			uintptr(unsafe.Pointer(&batch[0])), uintptr(len(batch)),
+			0, 0, 0)
+		if errno != 0 {
+			return nil, fmt.Errorf("cannot list mounts: %w", errno)
+		}
+		ids = append(ids, batch[:n]...)
+		if int(n) < len(batch) {
+			return ids, nil
+		}
+		last = batch[n-1]
+	}
+}
+
+// statMount returns the details about the mount with the specified (unique)
+// ID in the mount namespace with the specified ID. It returns the buffer used,
+// which might have been enlarged, for reuse.
+func statMount(nsid uint64, id uint64, buf []byte) (spacetest.MountEntry, []byte, error) {
+	req := mntIDReq{
+		size:  unix.MNT_ID_REQ_SIZE_VER1,
+		mntID: id,
+		param: statmountSBBasic | statmountMntBasic | statmountMntRoot | statmountMntPoint |
+			statmountFSType | statmountMntOpts | statmountFSSubtype | statmountSBSource,
+		mntNsID: nsid,
+	}
+	for {
+		_, _, errno := unix.Syscall6(unix.SYS_STATMOUNT,
+			uintptr(unsafe.Pointer(&req)),
+			uintptr(unsafe.Pointer(&buf[0])), uintptr(len(buf)),
+			0, 0, 0)
+		if errno == unix.EOVERFLOW && len(buf) < 1<<20 {
+			buf = make([]byte, 2*len(buf))
+			continue
+		}
+		if errno != 0 {
+			return spacetest.MountEntry{}, buf, fmt.Errorf("cannot stat mount: %w", errno)
+		}
+		break
+	}
+
+	u32 := func(offset int) uint32 { return binary.NativeEndian.Uint32(buf[offset:]) }
+	u64 := func(offset int) uint64 { return binary.NativeEndian.Uint64(buf[offset:]) }
+	mask := u64(smMask)
+	str := func(bit uint64, offset int) string {
+		if mask&bit == 0 {
+			return ""
+		}
+		s := buf[smStringsStart+int(u32(offset)):]
+		if end := bytes.IndexByte(s, 0); end >= 0 {
+			s = s[:end]
+		}
+		return string(s)
+	}
+
+	mount := spacetest.MountEntry{
+		ID:         uint64(u32(smMntIDOld)),
+		ParentID:   uint64(u32(smParentIDOld)),
+		Major:      u32(smSBDevMajor),
+		Minor:      u32(smSBDevMinor),
+		Root:       str(statmountMntRoot, smMntRoot),
+		MountPoint: str(statmountMntPoint, smMntPoint),
+		Options:    mountOptions(u64(smMntAttr)),
+		FSType:     str(statmountFSType, smFSType),
+		Source:     str(statmountSBSource, smSBSource),
+	}
+	if subtype := str(statmountFSSubtype, smFSSubtype); subtype != "" {
+		mount.FSType += "." + subtype
+	}
+	propagation := u64(smPropagation)
+	if propagation&unix.MS_SHARED != 0 {
+		mount.PeerGroup = u64(smPeerGroup)
+	}
+	if propagation&unix.MS_SLAVE != 0 {
+		mount.Master = u64(smMaster)
+	}
+	mount.Unbindable = propagation&unix.MS_UNBINDABLE != 0
+	mount.SuperOptions = superOptions(u32(smSBFlags), str(statmountMntOpts, smMntOpts))
+	return mount, buf, nil
+}
+
+// mountOptions returns the per-mount options for the passed MOUNT_ATTR_...
+// flags, in the same order as shown in mountinfo.
+func mountOptions(attrs uint64) []string {
+	options := []string{"rw"}
+	if attrs&unix.MOUNT_ATTR_RDONLY != 0 {
+		options[0] = "ro"
+	}
+	for _, opt := range []struct {
+		mask, attr uint64
+		name       string
+	}{
+		{unix.MOUNT_ATTR_NOSUID, unix.MOUNT_ATTR_NOSUID, "nosuid"},
+		{unix.MOUNT_ATTR_NODEV, unix.MOUNT_ATTR_NODEV, "nodev"},
+		{unix.MOUNT_ATTR_NOEXEC, unix.MOUNT_ATTR_NOEXEC, "noexec"},
+		{unix.MOUNT_ATTR__ATIME, unix.MOUNT_ATTR_NOATIME, "noatime"},
+		{unix.MOUNT_ATTR_NODIRATIME, unix.MOUNT_ATTR_NODIRATIME, "nodiratime"},
+		{unix.MOUNT_ATTR__ATIME, unix.MOUNT_ATTR_RELATIME, "relatime"},
+		{unix.MOUNT_ATTR_NOSYMFOLLOW, unix.MOUNT_ATTR_NOSYMFOLLOW, "nosymfollow"},
+		{unix.MOUNT_ATTR_IDMAP, unix.MOUNT_ATTR_IDMAP, "idmapped"},
+	} {
+		if attrs&opt.mask == opt.attr {
+			options = append(options, opt.name)
+		}
+	}
+	return options
+}
+
+// superOptions returns the superblock options for the passed superblock
+// flags and filesystem-specific options, in the same form as shown in
+// mountinfo.
+func superOptions(sbflags uint32, fsopts string) []string {
+	options := []string{"rw"}
+	if sbflags&sbRdonly != 0 {
+		options[0] = "ro"
+	}
+	for _, flag := range []struct {
+		flag uint32
+		name string
+	}{
+		{sbSynchronous, "sync"},
+		{sbDirsync, "dirsync"},
+		{sbLazytime, "lazytime"},
+	} {
+		if sbflags&flag.flag != 0 {
+			options = append(options, flag.name)
+		}
+	}
+	if fsopts == "" {
+		return options
+	}
+	for _, option := range strings.Split(fsopts, ",") {
+		options = append(options, spacetest.UnescapeOctal(option))
+	}
+	return options
+}
@@ -17,7 +17,9 @@ package mntns
 import (
 	"fmt"
 	"runtime"
+	"sync"
 
+	"github.com/thediveo/spacetest"
 	"golang.org/x/sys/unix"
 
 	. "github.com/onsi/ginkgo/v2" //nolint:staticcheck // ST1001 rule does not apply
@@ -27,7 +29,9 @@ import (
 // EnterTransient creates and enters a new mount namespace, returning a function
 // that needs to be defer'ed. It additionally remounts “/” in this new mount
 // namespace to set propagation of mount points to “private” to prevent mount
-// point changes to propagate back into the host.
+// point changes to propagate back into the host. Use the [WithPropagation] and
+// [WithSubtreePropagation] options to pick a different mount point propagation
+// for the whole tree or selected subtrees, respectively.
 //
 // Note: the current OS-level thread won't be unlocked when the calling unit
 // test returns, as we cannot undo unsharing filesystem attributes (using
@@ -46,87 +50,207 @@ import (
 //
 //	/* C */ mount("none", "/", NULL, flags, NULL
 //
+// EnterTransient is a thin wrapper around [EnterTransientE], failing the
+// current test in case EnterTransientE returns an error.
+//
 // [util-linux/unshare.c set_propagation]: https://github.com/util-linux/util-linux/blob/86b6684e7a215a0608bd130371bd7b3faae67aca/sys-utils/unshare.c#L160
 // [unshare(1)]: https://man7.org/linux/man-pages/man1/unshare.1.html
 // [util-linux/unshare.c UNSHARE_PROPAGATION_DEFAULT]: https://github.com/util-linux/util-linux/blob/86b6684e7a215a0608bd130371bd7b3faae67aca/sys-utils/unshare.c#L57
-func EnterTransient() func() {
+func EnterTransient(opts ...Option) func() {
 	GinkgoHelper()
 
+	leave, err := EnterTransientE(opts...)
+	Expect(err).NotTo(HaveOccurred())
+	return func() {
+		if err := leave(); err != nil {
+			panic(err.Error())
+		}
+	}
+}
+
+// EnterTransientE works like [EnterTransient], but instead of failing the
+// current test it returns an error in case the new mount namespace cannot be
+// created and entered. EnterTransientE thus can also be used outside Ginkgo
+// tests.
+//
+// The returned leave function needs to be called in order to switch the
+// calling OS-level thread back into the original mount namespace. The calling
+// go routine always stays locked to its OS-level thread, as we cannot undo
+// unsharing the filesystem attributes.
+func EnterTransientE(opts ...Option) (leave func() error, err error) {
 	runtime.LockOSThread() // ...kind of point of no return
 
-	callersMountNamespace, err := unix.Open("/proc/thread-self/ns/mnt", unix.O_RDONLY, 0)
-	Expect(err).NotTo(HaveOccurred(), "cannot determine current mount namespace from procfs")
+	callersMountNamespace, err := unix.Open("/proc/thread-self/ns/mnt", unix.O_RDONLY|unix.O_CLOEXEC, 0)
+	if err != nil {
+		return nil, fmt.Errorf("cannot determine current mount namespace from procfs: %w", err)
+	}
 
 	// Decouple some filesystem-related attributes of this thread from the ones
 	// of our process...
-	Expect(unix.Unshare(unix.CLONE_FS|unix.CLONE_NEWNS)).To(Succeed(),
-		"cannot create new mount namespace")
-	// Remount root to ensure that later mount point manipulations do not
-	// propagate back into our host, trashing it.
-	Expect(unix.Mount("none", "/", "/", unix.MS_REC|unix.MS_PRIVATE, "")).To(Succeed(),
-		"cannot change / mount propagation to private")
-
-	// Our cleanup cannot be DeferCleanup'ed, because we need to restore the current
-	// locked go routine, so that the defer rollback sequence is kept correct.
-	return func() {
+	if err := unix.Unshare(unix.CLONE_FS | unix.CLONE_NEWNS); err != nil {
+		_ = unix.Close(callersMountNamespace)
+		return nil, fmt.Errorf("cannot create new mount namespace: %w", err)
+	}
+	// Remount root to apply the requested mount point propagation; unless
+	// explicitly asked for shared propagation, this ensures that later mount
+	// point manipulations do not propagate back into our host, trashing it.
+	if err := applyPropagation(newOptions(opts...)); err != nil {
+		_ = unix.Setns(callersMountNamespace, 0)
+		_ = unix.Close(callersMountNamespace)
+		return nil, err
+	}
+
+	return func() error {
 		if err := unix.Setns(callersMountNamespace, 0); err != nil {
-			panic(fmt.Sprintf("cannot restore original mount namespace, reason: %s", err.Error()))
+			return fmt.Errorf("cannot restore original mount namespace, reason: %w", err)
 		}
 		_ = unix.Close(callersMountNamespace)
 		// do NOT unlock the OS-level thread, as we cannot undo unsharing CLONE_FS
-	}
+		return nil
+	}, nil
 }
 
 // NewTransient creates a new transient mount namespace that is kept alive by a
 // an idle OS-level thread; this idle thread is automatically terminated upon
 // returning from the current test.
-func NewTransient() (mntfd int, procfsroot string) {
+//
+// Same as [EnterTransient], NewTransient sets the propagation of mount points
+// to “private”, unless specified otherwise using options.
+//
+// NewTransient is a thin wrapper around [NewTransientNamespace], returning the
+// file descriptor of the new mount namespace.
+func NewTransient(opts ...Option) (mntfd int, procfsroot string) {
+	GinkgoHelper()
+
+	mntns, procfsroot := NewTransientNamespace(opts...)
+	return mntns.Fd(), procfsroot
+}
+
+// NewTransientNamespace creates a new transient mount namespace that is kept
+// alive by a an idle OS-level thread, returning a [spacetest.Namespace]
+// referencing it. The idle thread is automatically terminated and the returned
+// Namespace closed upon returning from the current test.
+func NewTransientNamespace(opts ...Option) (mntns *spacetest.Namespace, procfsroot string) {
 	GinkgoHelper()
 
+	mntns, procfsroot, release, err := NewTransientNamespaceE(opts...)
+	Expect(err).NotTo(HaveOccurred())
+	DeferCleanup(release)
+	return mntns, procfsroot
+}
+
+// NewTransientE works like [NewTransient], but instead of failing the current
+// test it returns an error in case the new mount namespace cannot be created.
+// NewTransientE thus can also be used outside Ginkgo tests.
+//
+// Instead of scheduling any cleanup, NewTransientE returns a release function
+// that the caller must call in order to close the returned file descriptor
+// and to terminate the idle OS-level thread.
+func NewTransientE(opts ...Option) (mntfd int, procfsroot string, release func(), err error) {
+	mntns, procfsroot, release, err := NewTransientNamespaceE(opts...)
+	if err != nil {
+		return -1, "", nil, err
+	}
+	return mntns.Fd(), procfsroot, release, nil
+}
+
+// NewTransientNamespaceE works like [NewTransientNamespace], but instead of
+// failing the current test it returns an error in case the new mount namespace
+// cannot be created. NewTransientNamespaceE thus can also be used outside
+// Ginkgo tests.
+//
+// Instead of scheduling any cleanup, NewTransientNamespaceE returns a release
+// function that the caller must call in order to close the returned Namespace
+// and to terminate the idle OS-level thread. Calling the release function more
+// than once is safe.
+func NewTransientNamespaceE(opts ...Option) (mntns *spacetest.Namespace, procfsroot string, release func(), err error) {
+	return newIdler(newOptions(opts...), nil)
+}
+
+// newIdler creates a new mount namespace with the mount point propagation
+// specified by the passed options that is kept alive by an idle OS-level
+// thread, returning a Namespace referencing it, the procfsroot path of the idle
+// thread, and a release function to close the Namespace and terminate the idle
+// thread. If setup is non-nil, it is called on the idle thread after the idle
+// thread entered the new mount namespace, in order to set up the mount
+// namespace further.
+func newIdler(o options, setup func() error) (mntns *spacetest.Namespace, procfsroot string, release func(), err error) {
 	// closing the done channel tells the Go routine we will kick off next to
 	// call it a day and terminate (well, unless the called fn is stuck).
 	done := make(chan struct{})
-	DeferCleanup(func() { close(done) })
 
 	// Kick off a separate Go routine which we then can lock to its OS-level
 	// thread and later dispose off because it is tainted due to unsharing the
 	// sharing of file attributes.
 	readyCh := make(chan idlerDetails)
 	go func() {
-		defer GinkgoRecover()
 		runtime.LockOSThread()
 
 		// Whatever is going to happen to us, make sure to unblock the receiving
-		// Go routine, and even if this is the zero value...
-		defer func() {
-			close(readyCh)
-		}()
-
-		// Decouple some filesystem-related attributes of this thread from the ones
-		// of our process...
-		Expect(unix.Unshare(unix.CLONE_FS|unix.CLONE_NEWNS)).To(Succeed(),
-			"cannot create new mount namespace")
-		// Remount root to ensure that later mount point manipulations do not
-		// propagate back into our host, trashing it.
-		Expect(unix.Mount("none", "/", "/", unix.MS_REC|unix.MS_PRIVATE, "")).To(
-			Succeed(), "cannot change / mount propagation to private")
-
-		readyCh <- idlerDetails{
-			mntnsfd: Current(),
-			TID:     unix.Gettid(),
+		// Go routine...
+		defer close(readyCh)
+
+		details := idle(o)
+		if details.err == nil && setup != nil {
+			if err := setup(); err != nil {
+				_ = details.mntns.Close()
+				details = idlerDetails{err: err}
+			}
 		}
+		readyCh <- details
 
 		<-done // ...idle around, then fall off the discworld...
 	}()
 	idlerInfo := <-readyCh
-	Expect(idlerInfo.mntnsfd).NotTo(BeZero())
+	if idlerInfo.err != nil {
+		close(done)
+		return nil, "", nil, idlerInfo.err
+	}
 	procfsroot = fmt.Sprintf("/proc/%d/root", idlerInfo.TID)
-	return idlerInfo.mntnsfd, procfsroot
+	var once sync.Once
+	return idlerInfo.mntns, procfsroot, func() {
+		once.Do(func() {
+			_ = idlerInfo.mntns.Close()
+			close(done)
+		})
+	}, nil
+}
+
+// idle sets up the calling OS-level thread to become attached to a new mount
+// namespace with the mount point propagation specified by the passed options
+// and returns the details about it.
+func idle(o options) idlerDetails {
+	// Decouple some filesystem-related attributes of this thread from the ones
+	// of our process...
+	if err := unix.Unshare(unix.CLONE_FS | unix.CLONE_NEWNS); err != nil {
+		return idlerDetails{err: fmt.Errorf("cannot create new mount namespace: %w", err)}
+	}
+	// Remount root to apply the requested mount point propagation; unless
+	// explicitly asked for shared propagation, this ensures that later mount
+	// point manipulations do not propagate back into our host, trashing it.
+	if err := applyPropagation(o); err != nil {
+		return idlerDetails{err: err}
+	}
+	fd, err := unix.Open("/proc/thread-self/ns/mnt", unix.O_RDONLY|unix.O_CLOEXEC, 0)
+	if err != nil {
+		return idlerDetails{err: fmt.Errorf("cannot determine new mount namespace from procfs: %w", err)}
+	}
+	mntns, err := spacetest.NewNamespace(fd)
+	if err != nil {
+		_ = unix.Close(fd)
+		return idlerDetails{err: err}
+	}
+	return idlerDetails{
+		mntns: mntns,
+		TID:   unix.Gettid(),
+	}
 }
 
 // idlerDetails passes information about an idler's TID and mount namespace
-// reference from the idler go routine to its creator.
+// reference from the idler go routine to its creator, or the error that
+// prevented the idler from setting up its mount namespace.
 type idlerDetails struct {
-	mntnsfd int
-	TID     int
+	mntns *spacetest.Namespace
+	TID   int
+	err   error
 }
@@ -16,6 +16,10 @@ package mntns
 
 import (
 	"os"
+	"path/filepath"
+
+	"github.com/thediveo/spacetest"
+	"golang.org/x/sys/unix"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -48,4 +52,24 @@ var _ = Describe("transient mount namespaces", Ordered, func() {
 		Expect(count).To(Equal(1), "didn't call fn")
 	})
 
+	It("creates a new transient mount namespace handle", func() {
+		mntns, procfsroot := NewTransientNamespace()
+		Expect(mntns.Type()).To(Equal(unix.CLONE_NEWNS))
+		Expect(mntns.Ino()).To(Equal(Ino(filepath.Join(procfsroot, "../ns/mnt"))))
+		Expect(mntns.Ino()).NotTo(Equal(CurrentIno()))
+	})
+
+	It("creates a new transient mount namespace without scheduling cleanups", func() {
+		mntnsfd, procfsroot, release, err := NewTransientE()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(mntnsfd).To(spacetest.BeNamespaceOfType(unix.CLONE_NEWNS))
+		Expect(mntnsfd).To(spacetest.BeSameNamespaceAs(filepath.Join(procfsroot, "../ns/mnt")))
+		Expect(ExecuteE(mntnsfd, func() {
+			Expect(mntnsfd).To(spacetest.BeCurrentNamespace())
+		})).To(Succeed())
+		release()
+		Expect(unix.Fstat(mntnsfd, &unix.Stat_t{})).To(MatchError(unix.EBADF))
+		Expect(release).NotTo(Panic())
+	})
+
 })
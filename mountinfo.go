@@ -0,0 +1,165 @@
+// Copyright 2025 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spacetest
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2" //nolint:staticcheck // ST1001 rule does not apply
+	. "github.com/onsi/gomega"    //nolint:staticcheck // ST1001 rule does not apply
+)
+
+// MountEntry describes a single mount of a mount namespace, along the lines of
+// [proc_pid_mountinfo(5)].
+//
+// [proc_pid_mountinfo(5)]: https://man7.org/linux/man-pages/man5/proc_pid_mountinfo.5.html
+type MountEntry struct {
+	ID           uint64   // mount ID, as shown in mountinfo.
+	ParentID     uint64   // mount ID of the parent mount.
+	Major        uint32   // major device number of the mounted filesystem.
+	Minor        uint32   // minor device number of the mounted filesystem.
+	Root         string   // root of the mount inside the mounted filesystem.
+	MountPoint   string   // mount point relative to the mount namespace's root.
+	Options      []string // per-mount options, such as “ro” and “nosuid”.
+	PeerGroup    uint64   // ID of the shared peer group, or zero if not shared.
+	Master       uint64   // ID of the peer group receiving from, or zero.
+	Unbindable   bool     // mount is unbindable.
+	FSType       string   // filesystem type, including any subtype.
+	Source       string   // filesystem-specific source, such as “/dev/sda1”.
+	SuperOptions []string // superblock options, such as “rw” and “size=1024k”.
+}
+
+// Propagation returns the propagation type of this mount in textual form,
+// that is, “private”, “shared”, “slave”, “shared,slave”, or “unbindable”.
+func (m MountEntry) Propagation() string {
+	switch {
+	case m.Unbindable:
+		return "unbindable"
+	case m.PeerGroup != 0 && m.Master != 0:
+		return "shared,slave"
+	case m.PeerGroup != 0:
+		return "shared"
+	case m.Master != 0:
+		return "slave"
+	}
+	return "private"
+}
+
+// ReadMountInfo returns the mounts parsed from the specified mountinfo file,
+// such as “/proc/thread-self/mountinfo”, in the order listed in the file.
+//
+// ReadMountInfo is a thin wrapper around [ReadMountInfoE], failing the current
+// test in case ReadMountInfoE returns an error.
+func ReadMountInfo(path string) []MountEntry {
+	GinkgoHelper()
+
+	mounts, err := ReadMountInfoE(path)
+	Expect(err).NotTo(HaveOccurred())
+	return mounts
+}
+
+// ReadMountInfoE works like [ReadMountInfo], but returns an error instead of
+// failing the current test.
+func ReadMountInfoE(path string) ([]MountEntry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read mount information: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+	var mounts []MountEntry
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		mount, err := parseMountInfoLine(scanner.Text())
+		if err != nil {
+			return nil, err
+		}
+		mounts = append(mounts, mount)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("cannot read mount information: %w", err)
+	}
+	return mounts, nil
+}
+
+// parseMountInfoLine parses a single mountinfo line in the form of "36 35 98:0
+// /root /mnt rw,noatime master:1 - ext3 /dev/root rw,errors=continue", where
+// the number of optional fields varies; see also proc_pid_mountinfo(5).
+func parseMountInfoLine(line string) (MountEntry, error) {
+	mountfields, fsfields, ok := strings.Cut(line, " - ")
+	fields := strings.Fields(mountfields)
+	superfields := strings.Fields(fsfields)
+	if !ok || len(fields) < 6 || len(superfields) < 3 {
+		return MountEntry{}, fmt.Errorf("invalid mountinfo line %q", line)
+	}
+	var mount MountEntry
+	var err error
+	if mount.ID, err = strconv.ParseUint(fields[0], 10, 64); err != nil {
+		return MountEntry{}, fmt.Errorf("invalid mount ID in mountinfo line %q", line)
+	}
+	if mount.ParentID, err = strconv.ParseUint(fields[1], 10, 64); err != nil {
+		return MountEntry{}, fmt.Errorf("invalid parent mount ID in mountinfo line %q", line)
+	}
+	major, minor, _ := strings.Cut(fields[2], ":")
+	majorno, err := strconv.ParseUint(major, 10, 32)
+	if err != nil {
+		return MountEntry{}, fmt.Errorf("invalid major device number in mountinfo line %q", line)
+	}
+	minorno, err := strconv.ParseUint(minor, 10, 32)
+	if err != nil {
+		return MountEntry{}, fmt.Errorf("invalid minor device number in mountinfo line %q", line)
+	}
+	mount.Major, mount.Minor = uint32(majorno), uint32(minorno)
+	mount.Root = UnescapeOctal(fields[3])
+	mount.MountPoint = UnescapeOctal(fields[4])
+	mount.Options = strings.Split(fields[5], ",")
+	for _, optional := range fields[6:] {
+		tag, value, _ := strings.Cut(optional, ":")
+		switch tag {
+		case "shared":
+			mount.PeerGroup, _ = strconv.ParseUint(value, 10, 64)
+		case "master":
+			mount.Master, _ = strconv.ParseUint(value, 10, 64)
+		case "unbindable":
+			mount.Unbindable = true
+		}
+	}
+	mount.FSType = UnescapeOctal(superfields[0])
+	mount.Source = UnescapeOctal(superfields[1])
+	for _, option := range strings.Split(superfields[2], ",") {
+		mount.SuperOptions = append(mount.SuperOptions, UnescapeOctal(option))
+	}
+	return mount, nil
+}
+
+// UnescapeOctal returns the passed mountinfo field with any octal escapes in
+// the form of “\ooo” replaced by the characters they represent.
+func UnescapeOctal(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+4 <= len(s) {
+			if ch, err := strconv.ParseUint(s[i+1:i+4], 8, 8); err == nil {
+				b.WriteByte(byte(ch))
+				i += 3
+				continue
+			}
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
@@ -0,0 +1,80 @@
+// Copyright 2025 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spacetest
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("mountinfo", func() {
+
+	It("parses mountinfo lines", func() {
+		Expect(parseMountInfoLine(
+			`36 35 98:7 /root /mnt\040point rw,noatime shared:1 master:2 - ext3 /dev/root rw,errors=continue,x=a\054b`)).
+			To(Equal(MountEntry{
+				ID:           36,
+				ParentID:     35,
+				Major:        98,
+				Minor:        7,
+				Root:         "/root",
+				MountPoint:   "/mnt point",
+				Options:      []string{"rw", "noatime"},
+				PeerGroup:    1,
+				Master:       2,
+				FSType:       "ext3",
+				Source:       "/dev/root",
+				SuperOptions: []string{"rw", "errors=continue", "x=a,b"},
+			}))
+		Expect(parseMountInfoLine(`36 35 98:0 / / rw unbindable - ext4 /dev/root rw`)).To(
+			HaveField("Unbindable", BeTrue()))
+	})
+
+	DescribeTable("rejects invalid mountinfo lines",
+		func(line string) {
+			Expect(parseMountInfoLine(line)).Error().To(HaveOccurred())
+		},
+		Entry("missing separator", "36 35 98:0 / / rw ext4 /dev/root rw"),
+		Entry("missing fields", "36 35 98:0 / - ext4 /dev/root rw"),
+		Entry("invalid ID", "x 35 98:0 / / rw - ext4 /dev/root rw"),
+		Entry("invalid parent ID", "36 x 98:0 / / rw - ext4 /dev/root rw"),
+		Entry("invalid major", "36 35 x:0 / / rw - ext4 /dev/root rw"),
+		Entry("invalid minor", "36 35 98:x / / rw - ext4 /dev/root rw"),
+	)
+
+	DescribeTable("returns propagation types",
+		func(mount MountEntry, expected string) {
+			Expect(mount.Propagation()).To(Equal(expected))
+		},
+		Entry(nil, MountEntry{}, "private"),
+		Entry(nil, MountEntry{PeerGroup: 1}, "shared"),
+		Entry(nil, MountEntry{Master: 1}, "slave"),
+		Entry(nil, MountEntry{PeerGroup: 1, Master: 2}, "shared,slave"),
+		Entry(nil, MountEntry{Unbindable: true}, "unbindable"),
+	)
+
+	It("unescapes mountinfo fields", func() {
+		Expect(UnescapeOctal(`/foo\040bar\`)).To(Equal(`/foo bar\`))
+		Expect(UnescapeOctal(`\12`)).To(Equal(`\12`))
+	})
+
+	It("reads mountinfo", func() {
+		Expect(ReadMountInfo("/proc/thread-self/mountinfo")).To(
+			ContainElement(HaveField("MountPoint", "/")))
+		Expect(ReadMountInfoE("/nonexisting")).Error().To(
+			MatchError(ContainSubstring("cannot read mount information")))
+	})
+
+})
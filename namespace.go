@@ -0,0 +1,243 @@
+// Copyright 2025 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spacetest
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+
+	"golang.org/x/sys/unix"
+
+	. "github.com/onsi/ginkgo/v2" //nolint:staticcheck // ST1001 rule does not apply
+	. "github.com/onsi/gomega"    //nolint:staticcheck // ST1001 rule does not apply
+)
+
+// Namespace is a typed handle to a Linux kernel namespace that owns an open
+// file descriptor referencing the namespace. In contrast to bare int file
+// descriptors, a Namespace knows its type of namespace, so mixing up, say,
+// network and mount namespaces becomes visible in test failure messages.
+//
+// The zero value is not usable; use [NewNamespace], [OpenNamespace],
+// [CurrentNamespace], or [NewTransientNamespace] instead.
+type Namespace struct {
+	fd   int
+	typ  int
+	dev  uint64
+	ino  uint64
+	nsid uint64
+}
+
+// Handle is either a bare file descriptor referencing a Linux kernel namespace,
+// or a [Namespace].
+type Handle interface{ ~int | *Namespace }
+
+// NamespaceRef references a Linux kernel namespace by an open file descriptor.
+// Functions accepting multiple namespaces at once, such as [Execute], take
+// NamespaceRefs, so that [Namespace] handles and bare file descriptors wrapped
+// in [Fd] can be mixed in the same call:
+//
+//	spacetest.Execute(fn, mntns, spacetest.Fd(netnsfd))
+type NamespaceRef interface {
+	// Fd returns the file descriptor referencing the namespace, or -1 if
+	// there is none.
+	Fd() int
+}
+
+// Fd is a bare file descriptor referencing a Linux kernel namespace; it
+// implements [NamespaceRef].
+type Fd int
+
+// Fd returns the file descriptor.
+func (fd Fd) Fd() int { return int(fd) }
+
+// Typed returns a [NamespaceRef] for the passed [Handle] that [Execute] and
+// friends only accept if it references a namespace of the specified type. The
+// per-type packages, such as netns, use Typed to reject, say, mount namespaces
+// when expecting network namespaces.
+func Typed[H Handle](h H, typ int) NamespaceRef {
+	if ns, ok := any(h).(*Namespace); ok {
+		return typedRef{NamespaceRef: ns, typ: typ}
+	}
+	return typedRef{NamespaceRef: Fd(FdOf(h)), typ: typ}
+}
+
+// typedRef is a NamespaceRef that must reference a namespace of a specific
+// type.
+type typedRef struct {
+	NamespaceRef
+	typ int
+}
+
+// NewNamespace returns a new Namespace taking ownership of the passed file
+// descriptor, or an error if the file descriptor doesn't reference a namespace.
+// In case of an error, the file descriptor is left untouched.
+func NewNamespace(fd int) (*Namespace, error) {
+	typ, err := typeOfFd(fd)
+	if err != nil {
+		return nil, err
+	}
+	var namespaceStat unix.Stat_t
+	if err := unix.Fstat(fd, &namespaceStat); err != nil {
+		return nil, fmt.Errorf("cannot stat %s namespace reference %d, reason: %w",
+			Name(typ), fd, err)
+	}
+	return &Namespace{
+		fd:   fd,
+		typ:  typ,
+		dev:  namespaceStat.Dev,
+		ino:  namespaceStat.Ino,
+		nsid: nsidOf(fd),
+	}, nil
+}
+
+// OpenNamespace returns a new Namespace for the namespace referenced either by
+// a file descriptor, a VFS path name, or another Namespace. For file
+// descriptors and Namespaces, OpenNamespace works on a duplicate, so the
+// caller's reference stays untouched.
+//
+// Additionally, OpenNamespace schedules a DeferCleanup of the returned
+// Namespace to be closed at the end of the current test.
+//
+// If the specified reference is invalid, OpenNamespace fails the current test.
+func OpenNamespace[R Reference](ref R) *Namespace {
+	GinkgoHelper()
+
+	var fd int
+	var err error
+	switch path := any(ref).(type) {
+	case string:
+		fd, err = unix.Open(path, unix.O_RDONLY|unix.O_CLOEXEC, 0)
+		Expect(err).NotTo(HaveOccurred(),
+			"cannot open namespace referenced as %q", path)
+	default:
+		fd, err = unix.FcntlInt(uintptr(FdOf(ref)), unix.F_DUPFD_CLOEXEC, 0)
+		Expect(err).NotTo(HaveOccurred(),
+			"cannot duplicate namespace reference %v", ref)
+	}
+	ns, err := NewNamespace(fd)
+	if err != nil {
+		_ = unix.Close(fd)
+	}
+	Expect(err).NotTo(HaveOccurred())
+	DeferCleanup(func() { _ = ns.Close() })
+	return ns
+}
+
+// Fd returns the file descriptor owned by this Namespace, or -1 if the
+// Namespace has already been closed or is nil. The caller must not close the
+// returned file descriptor.
+func (n *Namespace) Fd() int {
+	if n == nil {
+		return -1
+	}
+	return n.fd
+}
+
+// Type returns the type of this namespace as a CLONE_NEW* constant, such as
+// [unix.CLONE_NEWNET].
+func (n *Namespace) Type() int { return n.typ }
+
+// Ino returns the inode number of this namespace.
+func (n *Namespace) Ino() uint64 { return n.ino }
+
+// Dev returns the device number of the (nsfs) filesystem of this namespace.
+func (n *Namespace) Dev() uint64 { return n.dev }
+
+// ID returns the identity of this namespace, including its 64-bit namespace
+// ID where supported by the kernel.
+func (n *Namespace) ID() ID { return ID{Dev: n.dev, Ino: n.ino, NSID: n.nsid} }
+
+// Name returns the type name of this namespace, such as “net”.
+func (n *Namespace) Name() string { return Name(n.typ) }
+
+// String returns the textual representation of this namespace in the same
+// form as the links in /proc/$PID/ns, such as “net:[4026531840]”.
+func (n *Namespace) String() string {
+	return fmt.Sprintf("%s:[%d]", n.Name(), n.ino)
+}
+
+// Close the file descriptor owned by this Namespace. Close can be called
+// multiple times; only the first call actually closes the file descriptor and
+// subsequent calls return nil.
+func (n *Namespace) Close() error {
+	if n.fd < 0 {
+		return nil
+	}
+	fd := n.fd
+	n.fd = -1
+	return unix.Close(fd)
+}
+
+// Dup returns a new Namespace referencing the same namespace using a new file
+// descriptor. The caller is responsible for closing the returned Namespace.
+func (n *Namespace) Dup() (*Namespace, error) {
+	if n.fd < 0 {
+		return nil, fmt.Errorf("cannot duplicate closed namespace %s", n)
+	}
+	fd, err := unix.FcntlInt(uintptr(n.fd), unix.F_DUPFD_CLOEXEC, 0)
+	if err != nil {
+		return nil, fmt.Errorf("cannot duplicate namespace %s, reason: %w", n, err)
+	}
+	dup := *n
+	dup.fd = fd
+	return &dup, nil
+}
+
+// typeOfFd returns the type of the namespace referenced by the passed file
+// descriptor as a CLONE_NEW* constant, or an error if the file descriptor
+// doesn't reference a namespace.
+func typeOfFd(fd int) (int, error) {
+	typ, err := unix.IoctlRetInt(fd, NS_GET_NSTYPE)
+	if err != nil {
+		return 0, fmt.Errorf("cannot determine type of namespace, reason: %w", err)
+	}
+	return typ, nil
+}
+
+// FdOf returns the file descriptor of the passed namespace reference, or -1 if
+// the reference isn't a file descriptor or [Namespace], but a path instead.
+// FdOf accepts any [Handle], too.
+func FdOf[R Reference](ref R) int {
+	switch ref := any(ref).(type) {
+	case *Namespace:
+		return ref.Fd()
+	case int:
+		return ref
+	}
+	if v := reflect.ValueOf(ref); v.Kind() == reflect.Int {
+		return int(v.Int())
+	}
+	return -1
+}
+
+// handleFd returns the file descriptor of the passed namespace handle, which
+// must be either a file descriptor or a [Namespace]. Otherwise, handleFd
+// returns an error.
+func handleFd(h any) (int, error) {
+	switch h := h.(type) {
+	case *Namespace:
+		if h == nil {
+			return -1, errors.New("expected a namespace handle, got nil *Namespace")
+		}
+		return h.Fd(), nil
+	case int:
+		return h, nil
+	}
+	if v := reflect.ValueOf(h); v.Kind() == reflect.Int {
+		return int(v.Int()), nil
+	}
+	return -1, fmt.Errorf("expected a namespace handle (file descriptor or *Namespace), got %T", h)
+}
@@ -0,0 +1,212 @@
+// Copyright 2025 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spacetest
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/onsi/gomega/gcustom"
+	"github.com/onsi/gomega/types"
+	"golang.org/x/sys/unix"
+)
+
+// BeNamespaceOfType succeeds if actual references a Linux kernel namespace of
+// the specified type, such as [unix.CLONE_NEWNET]. Actual can be a file
+// descriptor, a VFS path name, an open [*os.File], or a [Namespace].
+func BeNamespaceOfType(typ int) types.GomegaMatcher {
+	details := &matchDetails{Expected: Name(typ)}
+	return gcustom.MakeMatcher(func(actual any) (bool, error) {
+		actualns, err := inspect(actual)
+		if err != nil {
+			return false, err
+		}
+		defer func() { _ = actualns.Close() }()
+		details.Actual = actualns.String()
+		return actualns.Type() == typ, nil
+	}).WithTemplate("Expected namespace {{.Data.Actual}}\n{{.To}} be of type {{.Data.Expected}}",
+		details)
+}
+
+// BeSameNamespaceAs succeeds if actual references the same Linux kernel
+// namespace as the expected reference. Both actual and expected can be file
+// descriptors, VFS path names, open [*os.File]s, or [Namespace]s.
+func BeSameNamespaceAs(expected any) types.GomegaMatcher {
+	details := &matchDetails{}
+	return gcustom.MakeMatcher(func(actual any) (bool, error) {
+		actualns, err := inspect(actual)
+		if err != nil {
+			return false, err
+		}
+		defer func() { _ = actualns.Close() }()
+		expectedns, err := inspect(expected)
+		if err != nil {
+			return false, fmt.Errorf("invalid expected namespace: %w", err)
+		}
+		defer func() { _ = expectedns.Close() }()
+		details.Actual = actualns.String()
+		details.Expected = expectedns.String()
+		return actualns.ID().Equal(expectedns.ID()), nil
+	}).WithTemplate("Expected namespace {{.Data.Actual}}\n{{.To}} be the same as namespace {{.Data.Expected}}",
+		details)
+}
+
+// BeCurrentNamespace succeeds if actual references the namespace the calling
+// OS-level thread is currently attached to for the type of namespace actual
+// references. Please note that the caller's go routine should be
+// thread-locked. Actual can be a file descriptor, a VFS path name, an open
+// [*os.File], or a [Namespace].
+func BeCurrentNamespace() types.GomegaMatcher {
+	details := &matchDetails{}
+	return gcustom.MakeMatcher(func(actual any) (bool, error) {
+		actualns, err := inspect(actual)
+		if err != nil {
+			return false, err
+		}
+		defer func() { _ = actualns.Close() }()
+		currentns, err := inspect("/proc/thread-self/ns/" + actualns.Name())
+		if err != nil {
+			return false, fmt.Errorf("cannot determine current namespace: %w", err)
+		}
+		defer func() { _ = currentns.Close() }()
+		details.Actual = actualns.String()
+		details.Expected = currentns.String()
+		return actualns.ID().Equal(currentns.ID()), nil
+	}).WithTemplate("Expected namespace {{.Data.Actual}}\n{{.To}} be the current namespace {{.Data.Expected}}",
+		details)
+}
+
+// BeOwnedByUserNamespace succeeds if actual references a Linux kernel
+// namespace that is owned by the expected user namespace. For user namespaces,
+// the owning user namespace is the parent user namespace. Both actual and
+// expected can be file descriptors, VFS path names, open [*os.File]s, or
+// [Namespace]s.
+func BeOwnedByUserNamespace(expected any) types.GomegaMatcher {
+	details := &matchDetails{}
+	return gcustom.MakeMatcher(func(actual any) (bool, error) {
+		actualns, err := inspect(actual)
+		if err != nil {
+			return false, err
+		}
+		defer func() { _ = actualns.Close() }()
+		expectedns, err := inspect(expected)
+		if err != nil {
+			return false, fmt.Errorf("invalid expected user namespace: %w", err)
+		}
+		defer func() { _ = expectedns.Close() }()
+		if expectedns.Type() != unix.CLONE_NEWUSER {
+			return false, fmt.Errorf("expected namespace %s is not a user namespace", expectedns)
+		}
+		ownerns, err := inspectRelated(actual, NS_GET_USERNS)
+		if err != nil {
+			return false, fmt.Errorf("cannot determine owning user namespace of %s: %w",
+				actualns, err)
+		}
+		defer func() { _ = ownerns.Close() }()
+		details.Actual = actualns.String()
+		details.Related = ownerns.String()
+		details.Expected = expectedns.String()
+		return ownerns.ID().Equal(expectedns.ID()), nil
+	}).WithTemplate("Expected namespace {{.Data.Actual}} owned by {{.Data.Related}}\n{{.To}} be owned by user namespace {{.Data.Expected}}",
+		details)
+}
+
+// BeChildNamespaceOf succeeds if actual references a hierarchical Linux kernel
+// namespace (that is, a PID or user namespace) that is a direct child of the
+// expected namespace. Both actual and expected can be file descriptors, VFS
+// path names, open [*os.File]s, or [Namespace]s.
+func BeChildNamespaceOf(expected any) types.GomegaMatcher {
+	details := &matchDetails{}
+	return gcustom.MakeMatcher(func(actual any) (bool, error) {
+		actualns, err := inspect(actual)
+		if err != nil {
+			return false, err
+		}
+		defer func() { _ = actualns.Close() }()
+		expectedns, err := inspect(expected)
+		if err != nil {
+			return false, fmt.Errorf("invalid expected parent namespace: %w", err)
+		}
+		defer func() { _ = expectedns.Close() }()
+		details.Actual = actualns.String()
+		details.Expected = expectedns.String()
+		parentns, err := inspectRelated(actual, NS_GET_PARENT)
+		if errors.Is(err, unix.EPERM) {
+			// no parent visible to us: either the initial namespace or outside
+			// our scope.
+			details.Related = "no visible parent"
+			return false, nil
+		}
+		if err != nil {
+			return false, fmt.Errorf("cannot determine parent namespace of %s: %w",
+				actualns, err)
+		}
+		defer func() { _ = parentns.Close() }()
+		details.Related = "parent " + parentns.String()
+		return parentns.ID().Equal(expectedns.ID()), nil
+	}).WithTemplate("Expected namespace {{.Data.Actual}} with {{.Data.Related}}\n{{.To}} be a child of namespace {{.Data.Expected}}",
+		details)
+}
+
+// matchDetails passes the textual representations of the namespaces involved
+// in a match to the failure message templates.
+type matchDetails struct {
+	Actual   string
+	Expected string
+	Related  string
+}
+
+// inspect returns a [Namespace] for the namespace referenced by actual, which
+// can be a file descriptor, a VFS path name, an open [*os.File], or a
+// [Namespace]. The returned Namespace works on its own file descriptor, so the
+// caller must close it, but must not close actual. In contrast to [Type] and
+// [Ino], inspect doesn't fail the current test, but instead returns an error,
+// as needed by matchers.
+func inspect(actual any) (*Namespace, error) {
+	var ns *Namespace
+	err := withFdE(actual, func(fd int) error {
+		dupfd, err := unix.FcntlInt(uintptr(fd), unix.F_DUPFD_CLOEXEC, 0)
+		if err != nil {
+			return fmt.Errorf("cannot duplicate namespace reference %d, reason: %w", fd, err)
+		}
+		ns, err = NewNamespace(dupfd)
+		if err != nil {
+			_ = unix.Close(dupfd)
+		}
+		return err
+	})
+	return ns, err
+}
+
+// inspectRelated returns a [Namespace] for the namespace related to the
+// namespace referenced by actual, as specified by the passed ioctl request,
+// such as [NS_GET_USERNS] or [NS_GET_PARENT]. The caller must close the
+// returned Namespace. The error returned by a failing ioctl request is the
+// bare [unix.Errno].
+func inspectRelated(actual any, request uint) (*Namespace, error) {
+	var ns *Namespace
+	err := withFdE(actual, func(fd int) error {
+		relfd, err := ioctlRetFd(fd, request)
+		if err != nil {
+			return err
+		}
+		ns, err = NewNamespace(relfd)
+		if err != nil {
+			_ = unix.Close(relfd)
+		}
+		return err
+	})
+	return ns, err
+}
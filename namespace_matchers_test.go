@@ -0,0 +1,124 @@
+// Copyright 2025 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spacetest
+
+import (
+	"os"
+	"runtime"
+
+	"golang.org/x/sys/unix"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	. "github.com/thediveo/fdooze"
+	. "github.com/thediveo/success"
+)
+
+var _ = Describe("namespace matchers", func() {
+
+	BeforeEach(func() {
+		goodfds := Filedescriptors()
+		DeferCleanup(func() {
+			Eventually(Filedescriptors).ShouldNot(HaveLeakedFds(goodfds))
+		})
+	})
+
+	It("rejects non-namespace actuals", func() {
+		Expect(BeNamespaceOfType(unix.CLONE_NEWNET).Match(42.0)).Error().To(
+			MatchError(ContainSubstring("expected a namespace reference")))
+		Expect(BeNamespaceOfType(unix.CLONE_NEWNET).Match("/")).Error().To(
+			MatchError(ContainSubstring("cannot determine type of namespace")))
+		Expect(BeSameNamespaceAs("/nonexisting").Match("/proc/self/ns/net")).Error().To(
+			MatchError(ContainSubstring("invalid expected namespace")))
+	})
+
+	It("matches the type of namespace", func() {
+		f := Successful(os.Open("/proc/self/ns/net"))
+		defer func() { _ = f.Close() }()
+		Expect(f).To(BeNamespaceOfType(unix.CLONE_NEWNET))
+		Expect(int(f.Fd())).To(BeNamespaceOfType(unix.CLONE_NEWNET))
+		Expect("/proc/self/ns/net").To(BeNamespaceOfType(unix.CLONE_NEWNET))
+		Expect(CurrentNamespace(unix.CLONE_NEWNET)).To(BeNamespaceOfType(unix.CLONE_NEWNET))
+
+		m := BeNamespaceOfType(unix.CLONE_NEWNS)
+		Expect(m.Match(f)).To(BeFalse())
+		Expect(m.FailureMessage(f)).To(MatchRegexp(
+			`^Expected namespace net:\[\d+\]\nto be of type mnt$`))
+	})
+
+	It("matches the same namespace", func() {
+		netns := CurrentNamespace(unix.CLONE_NEWNET)
+		Expect("/proc/self/ns/net").To(BeSameNamespaceAs(netns))
+		Expect(netns.Fd()).To(BeSameNamespaceAs("/proc/self/ns/net"))
+		Expect("/proc/self/ns/net").NotTo(BeSameNamespaceAs("/proc/self/ns/mnt"))
+
+		m := BeSameNamespaceAs("/proc/self/ns/mnt")
+		Expect(m.Match(netns)).To(BeFalse())
+		Expect(m.FailureMessage(netns)).To(Equal(
+			"Expected namespace " + netns.String() +
+				"\nto be the same as namespace " + OpenNamespace("/proc/self/ns/mnt").String()))
+	})
+
+	It("matches the current namespace", func() {
+		runtime.LockOSThread()
+		defer runtime.UnlockOSThread()
+
+		Expect(CurrentNamespace(unix.CLONE_NEWUTS)).To(BeCurrentNamespace())
+		Expect("/proc/thread-self/ns/ipc").To(BeCurrentNamespace())
+	})
+
+	It("matches the owning user namespace", func() {
+		netns := CurrentNamespace(unix.CLONE_NEWNET)
+		Expect(netns).To(BeOwnedByUserNamespace("/proc/self/ns/user"))
+		Expect(BeOwnedByUserNamespace("/proc/self/ns/net").Match(netns)).Error().To(
+			MatchError(ContainSubstring("is not a user namespace")))
+
+		m := BeOwnedByUserNamespace("/proc/self/ns/user")
+		Expect(m.Match(netns)).To(BeTrue())
+		Expect(m.NegatedFailureMessage(netns)).To(MatchRegexp(
+			`^Expected namespace net:\[\d+\] owned by user:\[\d+\]\nnot to be owned by user namespace user:\[\d+\]$`))
+	})
+
+	It("matches child namespaces", func() {
+		Expect(BeChildNamespaceOf("/proc/self/ns/user").Match("/proc/self/ns/net")).Error().To(
+			MatchError(ContainSubstring("cannot determine parent namespace")))
+
+		m := BeChildNamespaceOf("/proc/self/ns/user")
+		Expect(m.Match("/proc/self/ns/user")).To(BeFalse())
+		Expect(m.FailureMessage("/proc/self/ns/user")).To(MatchRegexp(
+			`^Expected namespace user:\[\d+\] with no visible parent\nto be a child of namespace user:\[\d+\]$`))
+	})
+
+	When("using transient namespaces", func() {
+
+		BeforeEach(func() {
+			if os.Getuid() != 0 {
+				Skip("needs root")
+			}
+		})
+
+		It("matches a new namespace", func() {
+			netns := NewTransientNamespace(unix.CLONE_NEWNET)
+			Expect(netns).NotTo(BeSameNamespaceAs("/proc/self/ns/net"))
+			Expect(netns).NotTo(BeCurrentNamespace())
+			Execute(func() {
+				Expect(netns).To(BeCurrentNamespace())
+			}, netns)
+			Expect(netns).To(BeOwnedByUserNamespace("/proc/self/ns/user"))
+		})
+
+	})
+
+})
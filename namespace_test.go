@@ -0,0 +1,133 @@
+// Copyright 2025 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spacetest
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+
+	"golang.org/x/sys/unix"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	. "github.com/thediveo/fdooze"
+	. "github.com/thediveo/success"
+)
+
+var _ = Describe("namespace handles", func() {
+
+	BeforeEach(func() {
+		goodfds := Filedescriptors()
+		DeferCleanup(func() {
+			Expect(Filedescriptors()).NotTo(HaveLeakedFds(goodfds))
+		})
+	})
+
+	It("rejects non-namespace fds", func() {
+		fd := Successful(unix.Open(".", unix.O_RDONLY, 0))
+		defer func() { _ = unix.Close(fd) }()
+		Expect(NewNamespace(fd)).Error().To(
+			MatchError(ContainSubstring("cannot determine type of namespace")))
+	})
+
+	It("returns namespace properties", func() {
+		fd := Successful(unix.Open("/proc/self/ns/net", unix.O_RDONLY, 0))
+		ns := Successful(NewNamespace(fd))
+		defer func() { _ = ns.Close() }()
+
+		var st unix.Stat_t
+		Expect(unix.Stat("/proc/self/ns/net", &st)).To(Succeed())
+
+		Expect(ns.Fd()).To(Equal(fd))
+		Expect(ns.Type()).To(Equal(unix.CLONE_NEWNET))
+		Expect(ns.Name()).To(Equal("net"))
+		Expect(ns.Ino()).To(Equal(st.Ino))
+		Expect(ns.Dev()).To(Equal(st.Dev))
+		Expect(ns.String()).To(Equal(Successful(os.Readlink("/proc/self/ns/net"))))
+		Expect(ns.String()).To(Equal(fmt.Sprintf("net:[%d]", st.Ino)))
+	})
+
+	It("closes only once", func() {
+		ns := Successful(NewNamespace(
+			Successful(unix.Open("/proc/self/ns/net", unix.O_RDONLY, 0))))
+		Expect(ns.Close()).To(Succeed())
+		Expect(ns.Fd()).To(Equal(-1))
+		Expect(ns.Close()).To(Succeed())
+		Expect(ns.Dup()).Error().To(MatchError(ContainSubstring("cannot duplicate closed namespace net:[")))
+	})
+
+	It("duplicates", func() {
+		ns := OpenNamespace("/proc/self/ns/uts")
+		dup := Successful(ns.Dup())
+		defer func() { _ = dup.Close() }()
+		Expect(dup.Fd()).NotTo(Equal(ns.Fd()))
+		Expect(dup.String()).To(Equal(ns.String()))
+		Expect(Type(dup.Fd())).To(Equal(unix.CLONE_NEWUTS))
+	})
+
+	It("opens namespaces from different references", func() {
+		runtime.LockOSThread()
+		defer runtime.UnlockOSThread()
+
+		byPath := OpenNamespace("/proc/thread-self/ns/ipc")
+		byFd := OpenNamespace(Current(unix.CLONE_NEWIPC))
+		byNamespace := OpenNamespace(byFd)
+		Expect(byFd.Fd()).NotTo(Equal(byNamespace.Fd()))
+		Expect(byFd.String()).To(Equal(byPath.String()))
+		Expect(byNamespace.String()).To(Equal(byPath.String()))
+
+		Expect(InterceptGomegaFailure(func() {
+			_ = OpenNamespace("/proc/me,myself,I")
+		})).To(MatchError(ContainSubstring("cannot open namespace referenced as")))
+		Expect(InterceptGomegaFailure(func() {
+			_ = OpenNamespace(-1)
+		})).To(MatchError(ContainSubstring("cannot duplicate namespace reference -1")))
+	})
+
+	It("works with Type and Ino", func() {
+		ns := CurrentNamespace(unix.CLONE_NEWNET)
+		Expect(Type(ns)).To(Equal(unix.CLONE_NEWNET))
+		Expect(Ino(ns, unix.CLONE_NEWNET)).To(Equal(CurrentIno(unix.CLONE_NEWNET)))
+		Expect(InterceptGomegaFailure(func() {
+			_ = Ino(ns, unix.CLONE_NEWUTS)
+		})).To(MatchError(ContainSubstring("not a uts namespace")))
+	})
+
+	When("being root", func() {
+
+		BeforeEach(func() {
+			if os.Getuid() != 0 {
+				Skip("needs root")
+			}
+		})
+
+		It("executes in namespaces passed as handles", func() {
+			netns := NewTransientNamespace(unix.CLONE_NEWNET)
+			utsns := NewTransientNamespace(unix.CLONE_NEWUTS)
+			Expect(netns.Type()).To(Equal(unix.CLONE_NEWNET))
+
+			count := 0
+			Execute(func() {
+				count++
+				Expect(CurrentIno(unix.CLONE_NEWNET)).To(Equal(netns.Ino()))
+				Expect(CurrentIno(unix.CLONE_NEWUTS)).To(Equal(utsns.Ino()))
+			}, netns, utsns)
+			Expect(count).To(Equal(1), "didn't call fn")
+		})
+
+	})
+
+})
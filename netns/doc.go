@@ -64,6 +64,15 @@ One end is located in the second network interfaces.
 As for the names of the VETH pair end variables, please refer to [Dupond et
 Dupont].
 
+# Named Network Namespaces
+
+When debugging failing tests, [NewNamed] creates a new network namespace that
+is additionally pinned in “/run/netns”, the place where iproute2 looks for named
+network namespaces. While the test runs, the network namespace thus can be
+inspected using “ip netns exec”. Combined with
+[spacetest.KeepPinsOnFailure], the pins of failed tests are kept for post-mortem
+inspection.
+
 [thediveo/notwork]: https://github.com/thediveo/notwork
 [Dupond et Dupont]: https://en.wikipedia.org/wiki/Thomson_and_Thompson
 */
@@ -16,17 +16,49 @@ package netns
 
 import (
 	"github.com/thediveo/spacetest"
+	"golang.org/x/sys/unix"
 
 	gi "github.com/onsi/ginkgo/v2"
 )
 
 // Execute fn synchronously in the network namespace referenced by the open file
-// descriptor netnsfd.
+// descriptor or [spacetest.Namespace] netnsfd.
+//
+// If netnsfd doesn't reference a network namespace, Execute fails the current
+// test.
 //
 // This is a convenience wrapper for [spacetest.Execute]; the latter allows to
 // specify multiple namespaces to switch into in a single Execute.
-func Execute(netnsfd int, fn func()) {
+func Execute[H spacetest.Handle](netnsfd H, fn func()) {
+	gi.GinkgoHelper()
+
+	spacetest.Execute(fn, spacetest.Typed(netnsfd, unix.CLONE_NEWNET))
+}
+
+// ExecuteE works like [Execute], but returns an error instead of failing the
+// current test in case switching into the network namespace or back fails.
+//
+// This is a convenience wrapper for [spacetest.ExecuteE].
+func ExecuteE[H spacetest.Handle](netnsfd H, fn func()) error {
+	return spacetest.ExecuteE(fn, spacetest.Typed(netnsfd, unix.CLONE_NEWNET))
+}
+
+// ExecuteValue works like [Execute], but returns the value returned by the
+// passed fn.
+//
+// This is a convenience wrapper for [spacetest.ExecuteValue].
+func ExecuteValue[T any, H spacetest.Handle](netnsfd H, fn func() T) T {
+	gi.GinkgoHelper()
+
+	return spacetest.ExecuteValue(fn, spacetest.Typed(netnsfd, unix.CLONE_NEWNET))
+}
+
+// ExecuteErr works like [Execute], but returns the error returned by the
+// passed fn.
+//
+// This is a convenience wrapper for [spacetest.ExecuteErr].
+func ExecuteErr[H spacetest.Handle](netnsfd H, fn func() error) error {
 	gi.GinkgoHelper()
 
-	spacetest.Execute(fn, netnsfd)
+	return spacetest.ExecuteErr(fn, spacetest.Typed(netnsfd, unix.CLONE_NEWNET))
 }
@@ -15,8 +15,13 @@
 package netns
 
 import (
+	"errors"
+	"net"
 	"os"
 
+	"github.com/thediveo/spacetest"
+	"golang.org/x/sys/unix"
+
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 )
@@ -39,4 +44,37 @@ var _ = Describe("executing in other network namespaces", Ordered, func() {
 		Expect(count).To(Equal(1), "didn't call fn")
 	})
 
+	It("executes a func in a network namespace passed as a handle", func() {
+		netns := NewTransientNamespace()
+		count := 0
+		Execute(netns, func() {
+			count++
+			Expect(CurrentIno()).To(Equal(netns.Ino()))
+		})
+		Expect(count).To(Equal(1), "didn't call fn")
+	})
+
+	It("returns values and errors from a different network namespace", func() {
+		netns := NewTransient()
+		Expect(ExecuteValue(netns, func() []net.Interface {
+			ifaces, _ := net.Interfaces()
+			return ifaces
+		})).To(ConsistOf(HaveField("Name", "lo")))
+
+		Expect(ExecuteErr(netns, func() error {
+			_, err := net.InterfaceByName("lo")
+			return err
+		})).To(Succeed())
+		Expect(ExecuteErr(netns, func() error { return errors.New("D'oh!") })).To(
+			MatchError("D'oh!"))
+	})
+
+	It("rejects namespaces of other types", func() {
+		mntns := spacetest.CurrentNamespace(unix.CLONE_NEWNS)
+		Expect(ExecuteE(mntns, func() {})).To(MatchError("not a net namespace, but mnt"))
+		Expect(InterceptGomegaFailure(func() {
+			Execute(mntns.Fd(), func() {})
+		})).To(MatchError(ContainSubstring("not a net namespace, but mnt")))
+	})
+
 })
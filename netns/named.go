@@ -0,0 +1,67 @@
+// Copyright 2025 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package netns
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/thediveo/spacetest"
+	"golang.org/x/sys/unix"
+
+	. "github.com/onsi/ginkgo/v2" //nolint:staticcheck // ST1001 rule does not apply
+	. "github.com/onsi/gomega"    //nolint:staticcheck // ST1001 rule does not apply
+)
+
+// RunDir is the directory where iproute2's “ip netns” expects named network
+// namespaces to be pinned.
+const RunDir = "/run/netns"
+
+// NewNamed creates a new network namespace and pins it as RunDir/name, so that
+// it shows up in “ip netns list” and can be inspected using, for instance, “ip
+// netns exec name”. NewNamed returns a file descriptor referencing the new
+// network namespace.
+//
+// NewNamed schedules Ginkgo deferred cleanups in order to unpin the network
+// namespace and to close the returned file descriptor; see also
+// [spacetest.Pin] and [spacetest.KeepPinsOnFailure]. The caller thus must not
+// close the file descriptor returned.
+func NewNamed(name string) int {
+	GinkgoHelper()
+
+	Expect(os.MkdirAll(RunDir, 0o755)).To(Succeed())
+	netnsfd := NewTransient()
+	spacetest.Pin(netnsfd, filepath.Join(RunDir, name))
+	return netnsfd
+}
+
+// NewNamedE works like [NewNamed], but returns an error instead of failing the
+// current test. The caller is responsible for closing the returned file
+// descriptor and for calling the returned unpin function.
+func NewNamedE(name string) (netnsfd int, unpin func() error, err error) {
+	if err := os.MkdirAll(RunDir, 0o755); err != nil {
+		return -1, nil, err
+	}
+	netnsfd, err = NewTransientE()
+	if err != nil {
+		return -1, nil, err
+	}
+	unpin, err = spacetest.PinE(netnsfd, filepath.Join(RunDir, name))
+	if err != nil {
+		_ = unix.Close(netnsfd)
+		return -1, nil, err
+	}
+	return netnsfd, unpin, nil
+}
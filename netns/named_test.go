@@ -0,0 +1,70 @@
+// Copyright 2025 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package netns
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/thediveo/spacetest"
+	"golang.org/x/sys/unix"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	. "github.com/thediveo/fdooze"
+)
+
+var _ = Describe("named network namespaces", Ordered, func() {
+
+	BeforeAll(func() {
+		if os.Getuid() != 0 {
+			Skip("needs root")
+		}
+	})
+
+	BeforeEach(func() {
+		goodfds := Filedescriptors()
+		goodns := spacetest.HeldNamespaces()
+		DeferCleanup(func() {
+			Expect(Filedescriptors()).NotTo(HaveLeakedFds(goodfds))
+			Expect(spacetest.HeldNamespaces()).NotTo(spacetest.HaveLeakedNamespaces(goodns))
+		})
+	})
+
+	It("pins a new network namespace iproute2-style", func() {
+		name := "spacetest-named-" + filepath.Base(GinkgoT().TempDir())
+		pinpath := filepath.Join(RunDir, name)
+		DeferCleanup(func() {
+			Expect(pinpath).NotTo(BeAnExistingFile())
+		})
+		netnsfd := NewNamed(name)
+		Expect(pinpath).To(spacetest.BeSameNamespaceAs(netnsfd))
+	})
+
+	It("returns errors instead of failing", func() {
+		name := "spacetest-named-" + filepath.Base(GinkgoT().TempDir())
+		netnsfd, unpin, err := NewNamedE(name)
+		Expect(err).NotTo(HaveOccurred())
+		defer func() { _ = unix.Close(netnsfd) }()
+		Expect(filepath.Join(RunDir, name)).To(spacetest.BeSameNamespaceAs(netnsfd))
+
+		_, _, err = NewNamedE(name)
+		Expect(err).To(MatchError(ContainSubstring("cannot create pin file")))
+
+		Expect(unpin()).To(Succeed())
+		Expect(filepath.Join(RunDir, name)).NotTo(BeAnExistingFile())
+	})
+
+})
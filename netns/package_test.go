@@ -17,10 +17,14 @@ package netns
 import (
 	"testing"
 
+	"github.com/thediveo/spacetest"
+
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 )
 
+var _ = spacetest.AuditThreadNamespaces()
+
 func TestNetns(t *testing.T) {
 	RegisterFailHandler(Fail)
 	RunSpecs(t, "spacetest/netns package")
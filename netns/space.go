@@ -34,6 +34,18 @@ func Current() int {
 	return spacetest.Current(unix.CLONE_NEWNET)
 }
 
+// CurrentNamespace returns a [spacetest.Namespace] referencing the calling
+// OS-level thread's current network namespace. Please note that the caller's go
+// routine should be thread-locked ([runtime.LockOSThread]).
+//
+// Additionally, CurrentNamespace schedules a [gi.DeferCleanup] of the returned
+// Namespace to be closed at the end of the current test.
+func CurrentNamespace() *spacetest.Namespace {
+	gi.GinkgoHelper()
+
+	return spacetest.CurrentNamespace(unix.CLONE_NEWNET)
+}
+
 // CurrentIno returns the identification of the network namespace in form of a
 // inode number for the current OS-level thread.
 func CurrentIno() uint64 {
@@ -43,10 +55,11 @@ func CurrentIno() uint64 {
 }
 
 // Ino returns the identification (in form of an inode number) of the passed
-// network namespace, either referenced by a file descriptor or a VFS path name.
+// network namespace, either referenced by a file descriptor, a VFS path name,
+// or a [spacetest.Namespace].
 //
 // If the specified reference is invalid, Ino fails the current test.
-func Ino[R ~int | ~string](netns R) uint64 {
+func Ino[R spacetest.Reference](netns R) uint64 {
 	gi.GinkgoHelper()
 
 	return spacetest.Ino(netns, unix.CLONE_NEWNET)
@@ -46,3 +46,31 @@ func NewTransient() int {
 
 	return spacetest.NewTransient(unix.CLONE_NEWNET)
 }
+
+// NewTransientNamespace creates a new network namespace, but doesn't enter it.
+// Instead, it returns a [spacetest.Namespace] referencing the new network
+// namespace. NewTransientNamespace also schedules a Ginkgo deferred cleanup in
+// order to close the returned Namespace.
+func NewTransientNamespace() *spacetest.Namespace {
+	GinkgoHelper()
+
+	return spacetest.NewTransientNamespace(unix.CLONE_NEWNET)
+}
+
+// EnterTransientE works like [EnterTransient], but returns an error instead of
+// failing the current test. The returned leave function needs to be called in
+// order to switch back into the original network namespace.
+//
+// This is a convenience wrapper for [spacetest.EnterTransientE].
+func EnterTransientE() (leave func() error, err error) {
+	return spacetest.EnterTransientE(unix.CLONE_NEWNET)
+}
+
+// NewTransientE works like [NewTransient], but returns an error instead of
+// failing the current test. The caller is responsible for closing the returned
+// file descriptor.
+//
+// This is a convenience wrapper for [spacetest.NewTransientE].
+func NewTransientE() (int, error) {
+	return spacetest.NewTransientE(unix.CLONE_NEWNET)
+}
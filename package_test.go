@@ -21,6 +21,8 @@ import (
 	. "github.com/onsi/gomega"
 )
 
+var _ = AuditThreadNamespaces()
+
 func TestSpace /*DRY*/ (t *testing.T) {
 	RegisterFailHandler(Fail)
 	RunSpecs(t, "spacetest package")
@@ -0,0 +1,217 @@
+// Copyright 2025 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spacetest
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+
+	"github.com/thediveo/ioctl"
+	"golang.org/x/sys/unix"
+
+	. "github.com/onsi/ginkgo/v2" //nolint:staticcheck // ST1001 rule does not apply
+	. "github.com/onsi/gomega"    //nolint:staticcheck // ST1001 rule does not apply
+)
+
+// pidfdThread is the PIDFD_THREAD flag for pidfd_open(2), which allows opening
+// a pidfd for an individual thread instead of a thread group leader (Linux
+// 6.9+).
+const pidfdThread = unix.O_EXCL
+
+// pidfdGetPIDForChildrenNamespace is the pidfd ioctl request returning a file
+// descriptor referencing the PID namespace for the children of the process or
+// thread referenced by a pidfd (Linux 6.11+).
+var pidfdGetPIDForChildrenNamespace = ioctl.IO(_PIDFS_IOCTL_MAGIC, 6)
+
+// newTransientPIDNamespace creates a new PID namespace on a throw-away OS-level
+// thread, returning a Namespace referencing it. The new PID namespace doesn't
+// contain any process yet, so the first process created in it will become its
+// PID 1.
+//
+// As procfs refuses to hand out references to PID namespaces without any
+// process, newTransientPIDNamespace needs Linux 6.11+; on older kernels it
+// returns an error wrapping [errors.ErrUnsupported].
+func newTransientPIDNamespace() (*Namespace, error) {
+	// Go checks once whether pidfds are fully supported, forking a short-lived
+	// child in the process. Should this child ever get forked into our new PID
+	// namespace, it would become its PID 1 and immediately terminate it for
+	// good. So make sure that Go has already done its check.
+	if p, err := os.FindProcess(os.Getpid()); err == nil {
+		_ = p.Release()
+	}
+
+	type result struct {
+		ns  *Namespace
+		err error
+	}
+	resultCh := make(chan result)
+	go func() {
+		// never unlock, as this thread is going to be tainted.
+		runtime.LockOSThread()
+
+		if err := unix.Unshare(unix.CLONE_NEWPID); err != nil {
+			resultCh <- result{err: fmt.Errorf("cannot create new pid namespace: %w", err)}
+			return
+		}
+		ns, err := pidForChildren()
+		resultCh <- result{ns: ns, err: err}
+	}()
+	r := <-resultCh
+	return r.ns, r.err
+}
+
+// pidForChildren returns a Namespace referencing the PID namespace for the
+// children of the calling OS-level thread.
+func pidForChildren() (*Namespace, error) {
+	nsfd, err := unix.Open("/proc/thread-self/ns/pid_for_children", unix.O_RDONLY|unix.O_CLOEXEC, 0)
+	if errors.Is(err, unix.ENOENT) {
+		// procfs refuses to hand out references to PID namespaces without a
+		// PID 1, so we need to ask via a pidfd for our thread instead.
+		nsfd, err = pidForChildrenFromPidfd()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot determine new pid namespace: %w", err)
+	}
+	ns, err := NewNamespace(nsfd)
+	if err != nil {
+		_ = unix.Close(nsfd)
+		return nil, err
+	}
+	return ns, nil
+}
+
+// pidForChildrenFromPidfd returns a file descriptor referencing the PID
+// namespace for the children of the calling OS-level thread, using a pidfd
+// referencing the thread. Kernels before Linux 6.9 reject PIDFD_THREAD with
+// EINVAL, and kernels before Linux 6.11 don't know the ioctl and return ENOTTY
+// instead; in both cases, pidForChildrenFromPidfd returns an error wrapping
+// [errors.ErrUnsupported].
+func pidForChildrenFromPidfd() (int, error) {
+	pidfd, err := unix.PidfdOpen(unix.Gettid(), pidfdThread)
+	if errors.Is(err, unix.EINVAL) {
+		return -1, unsupportedKernel("referencing pid namespaces without processes", "6.11", err)
+	}
+	if err != nil {
+		return -1, fmt.Errorf("cannot open pidfd of thread: %w", err)
+	}
+	defer func() { _ = unix.Close(pidfd) }()
+	nsfd, err := ioctlRetFd(pidfd, pidfdGetPIDForChildrenNamespace)
+	if errors.Is(err, unix.ENOTTY) || errors.Is(err, unix.EINVAL) {
+		return -1, unsupportedKernel("referencing pid namespaces without processes", "6.11", err)
+	}
+	return nsfd, err
+}
+
+// unsupportedKernel returns an error wrapping [errors.ErrUnsupported] as well
+// as the passed error, telling that the specified functionality needs at least
+// the specified Linux kernel version.
+func unsupportedKernel(what string, version string, err error) error {
+	return fmt.Errorf("%s needs Linux %s+: %w (%w)", what, version, errors.ErrUnsupported, err)
+}
+
+// StartPID1 starts the passed command as PID 1 of the PID namespace referenced
+// by the passed file descriptor or [Namespace], such as returned by
+// [NewTransient] for unix.CLONE_NEWPID. The command gets a private /proc for
+// its PID namespace in a transient mount namespace. This allows testing PID
+// 1-specific behavior, such as reaping orphaned processes, without resorting
+// to spacer services.
+//
+// The passed PID namespace must not contain any processes yet, as otherwise
+// the command won't become PID 1. Mounting the private /proc needs the procfs
+// “pidns” mount option of Linux 6.15+; on older kernels, StartPID1 fails. Please note that after the PID 1 of a PID
+// namespace has terminated, no new processes can be created in this PID
+// namespace anymore.
+//
+// StartPID1 schedules a Ginkgo deferred cleanup to kill the command and wait
+// for it, unless the caller already waited for the command to terminate.
+//
+// StartPID1 is a thin wrapper around [StartPID1E], failing the current test in
+// case StartPID1E returns an error.
+func StartPID1[H Handle](pidns H, cmd *exec.Cmd) {
+	GinkgoHelper()
+
+	Expect(StartPID1E(pidns, cmd)).To(Succeed())
+	DeferCleanup(func() {
+		if cmd.ProcessState != nil {
+			return
+		}
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+	})
+}
+
+// StartPID1E works like [StartPID1], but returns an error instead of failing
+// the current test. The caller is responsible for waiting for the command to
+// terminate.
+//
+// StartPID1E starts the command from a throw-away OS-level thread attached to
+// a transient mount namespace with a procfs instance for the passed PID
+// namespace mounted onto /proc, using the procfs “pidns” mount option. As the
+// procfs instance otherwise would always belong to the PID namespace of the
+// mounting thread itself, StartPID1E cannot fall back to a different mount
+// method on kernels before Linux 6.15 and instead returns an error wrapping
+// [errors.ErrUnsupported].
+func StartPID1E[H Handle](pidns H, cmd *exec.Cmd) error {
+	pidnsfd := FdOf(pidns)
+	typ, err := typeOfFd(pidnsfd)
+	if err != nil {
+		return err
+	}
+	if typ != unix.CLONE_NEWPID {
+		return fmt.Errorf("expected a pid namespace, got %s", Name(typ))
+	}
+	var startErr error
+	if err := goThrowaway(func() {
+		startErr = cmd.Start()
+	}, func() error {
+		return attachPID1(pidnsfd)
+	}); err != nil {
+		return err
+	}
+	return startErr
+}
+
+// attachPID1 attaches the calling OS-level thread to a new mount namespace
+// with a private /proc for the PID namespace referenced by pidnsfd, and then
+// to this PID namespace for the children of the calling thread. The calling
+// thread must be thrown away afterwards.
+func attachPID1(pidnsfd int) error {
+	if err := unix.Unshare(unix.CLONE_FS | unix.CLONE_NEWNS); err != nil {
+		return fmt.Errorf("cannot create new mount namespace: %w", err)
+	}
+	// Remount root to ensure that our /proc mount doesn't propagate back into
+	// our host.
+	if err := unix.Mount("none", "/", "", unix.MS_REC|unix.MS_PRIVATE, ""); err != nil {
+		return fmt.Errorf("cannot change / mount propagation to private: %w", err)
+	}
+	if err := unix.Mount("proc", "/proc", "proc",
+		unix.MS_NODEV|unix.MS_NOEXEC|unix.MS_NOSUID|unix.MS_RELATIME,
+		"pidns=/proc/self/fd/"+strconv.Itoa(pidnsfd)); err != nil {
+		// Kernels not knowing the “pidns” option reject it as invalid.
+		if errors.Is(err, unix.EINVAL) {
+			return fmt.Errorf("cannot mount private /proc: %w",
+				unsupportedKernel("mounting procfs for a different pid namespace", "6.15", err))
+		}
+		return fmt.Errorf("cannot mount private /proc: %w", err)
+	}
+	if err := unix.Setns(pidnsfd, unix.CLONE_NEWPID); err != nil {
+		return fmt.Errorf("cannot switch into pid namespace: %w", err)
+	}
+	return nil
+}
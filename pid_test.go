@@ -0,0 +1,134 @@
+// Copyright 2025 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spacetest
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"strings"
+
+	"golang.org/x/sys/unix"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	. "github.com/thediveo/success"
+)
+
+var _ = Describe("PID namespaces", func() {
+
+	BeforeEach(func() {
+		if os.Getuid() != 0 {
+			Skip("needs root")
+		}
+	})
+
+	// transientPIDNamespace returns a new transient PID namespace, skipping
+	// the current test on kernels not supporting PID namespaces without any
+	// processes.
+	transientPIDNamespace := func() *Namespace {
+		GinkgoHelper()
+		pidns, err := NewTransientNamespaceE(unix.CLONE_NEWPID)
+		if errors.Is(err, errors.ErrUnsupported) {
+			Skip(err.Error())
+		}
+		Expect(err).NotTo(HaveOccurred())
+		DeferCleanup(func() { _ = pidns.Close() })
+		return pidns
+	}
+
+	// startPID1 starts the passed command as PID 1 of the passed PID
+	// namespace, skipping the current test on kernels not supporting mounting
+	// procfs instances for other PID namespaces.
+	startPID1 := func(pidns *Namespace, cmd *exec.Cmd) {
+		GinkgoHelper()
+		err := StartPID1E(pidns, cmd)
+		if errors.Is(err, errors.ErrUnsupported) {
+			Skip(err.Error())
+		}
+		Expect(err).NotTo(HaveOccurred())
+		DeferCleanup(func() {
+			if cmd.ProcessState != nil {
+				return
+			}
+			_ = cmd.Process.Kill()
+			_ = cmd.Wait()
+		})
+	}
+
+	It("creates a transient PID namespace", func() {
+		pidnsfd := transientPIDNamespace().Fd()
+		Expect(pidnsfd).To(BeNamespaceOfType(unix.CLONE_NEWPID))
+		Expect(pidnsfd).NotTo(BeSameNamespaceAs("/proc/self/ns/pid"))
+		Expect(pidnsfd).To(BeChildNamespaceOf("/proc/self/ns/pid"))
+
+		// The first process started in the new PID namespace becomes its PID
+		// 1.
+		cmd := exec.Command("/bin/sh", "-c", "echo $$")
+		var out []byte
+		Execute(func() {
+			out = Successful(cmd.Output())
+		}, Fd(pidnsfd))
+		Expect(strings.TrimSpace(string(out))).To(Equal("1"))
+	})
+
+	It("creates a transient PID namespace without scheduling cleanups", func() {
+		pidnsfd, err := NewTransientE(unix.CLONE_NEWPID)
+		if errors.Is(err, errors.ErrUnsupported) {
+			Skip(err.Error())
+		}
+		Expect(err).NotTo(HaveOccurred())
+		Expect(pidnsfd).To(BeNamespaceOfType(unix.CLONE_NEWPID))
+		Expect(unix.Close(pidnsfd)).To(Succeed())
+
+		pidns := Successful(NewTransientNamespaceE(unix.CLONE_NEWPID))
+		Expect(pidns.Type()).To(Equal(unix.CLONE_NEWPID))
+		Expect(pidns.Close()).To(Succeed())
+	})
+
+	It("starts a command as PID 1 with a private /proc", func() {
+		ownProcSelf := Successful(os.Readlink("/proc/self"))
+
+		pidns := transientPIDNamespace()
+		var stdout strings.Builder
+		cmd := exec.Command("/bin/sh", "-c", "echo $$; cat /proc/1/comm; readlink /proc/self/ns/pid")
+		cmd.Stdout = &stdout
+		startPID1(pidns, cmd)
+		Expect(cmd.Wait()).To(Succeed())
+		Expect(strings.Fields(stdout.String())).To(Equal([]string{"1", "sh", pidns.String()}))
+
+		Expect(os.Readlink("/proc/self")).To(Equal(ownProcSelf))
+	})
+
+	It("rejects starting a command in a non-PID namespace", func() {
+		Expect(StartPID1E(NewTransient(unix.CLONE_NEWNET), exec.Command("/bin/true"))).To(
+			MatchError("expected a pid namespace, got net"))
+	})
+
+	It("reports unsupported kernels", func() {
+		err := unsupportedKernel("frobnicating", "6.66", unix.ENOTTY)
+		Expect(err).To(MatchError(errors.ErrUnsupported))
+		Expect(err).To(MatchError(unix.ENOTTY))
+		Expect(err.Error()).To(HavePrefix("frobnicating needs Linux 6.66+"))
+	})
+
+	It("rejects starting an already started command", func() {
+		cmd := exec.Command("/bin/true")
+		startPID1(transientPIDNamespace(), cmd)
+		Expect(StartPID1E(transientPIDNamespace(), cmd)).To(
+			MatchError(ContainSubstring("already started")))
+	})
+
+})
@@ -0,0 +1,101 @@
+// Copyright 2025 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spacetest
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"sync/atomic"
+
+	"golang.org/x/sys/unix"
+
+	. "github.com/onsi/ginkgo/v2" //nolint:staticcheck // ST1001 rule does not apply
+	. "github.com/onsi/gomega"    //nolint:staticcheck // ST1001 rule does not apply
+)
+
+// keepPins indicates whether pins of failed specs should be kept for
+// post-mortem inspection.
+var keepPins atomic.Bool
+
+// KeepPinsOnFailure controls whether namespaces pinned using [Pin] are kept
+// pinned after a failed spec, instead of getting unpinned as usual. This allows
+// post-mortem inspection of the namespaces involved in a failed spec, for
+// instance, using “ip netns exec” or “nsenter --net=”. The kept pins need to
+// be removed manually afterwards.
+//
+// KeepPinsOnFailure returns true, so it can be conveniently used in a
+// package-level variable declaration:
+//
+//	var _ = spacetest.KeepPinsOnFailure(true)
+func KeepPinsOnFailure(keep bool) bool {
+	keepPins.Store(keep)
+	return true
+}
+
+// Pin keeps the namespace referenced either by a file descriptor, a VFS path
+// name, or a [Namespace] alive beyond the lifetime of its references by
+// bind-mounting it onto a new file at the specified path. Pin fails the current
+// test if the file already exists.
+//
+// Pin schedules a DeferCleanup to unmount and remove the pin at the end of the
+// current test, unless the spec failed and [KeepPinsOnFailure] has been
+// enabled.
+//
+// Pinning requires CAP_SYS_ADMIN.
+func Pin[R Reference](ref R, path string) {
+	GinkgoHelper()
+
+	unpin, err := PinE(ref, path)
+	Expect(err).NotTo(HaveOccurred())
+	DeferCleanup(func() {
+		if keepPins.Load() && CurrentSpecReport().Failed() {
+			GinkgoWriter.Printf("keeping pinned namespace at %s\n", path)
+			return
+		}
+		Expect(unpin()).To(Succeed())
+	})
+}
+
+// PinE works like [Pin], but returns an error instead of failing the current
+// test. It returns a function to unmount and remove the pin that the caller is
+// responsible to call when the pin isn't needed anymore.
+func PinE[R Reference](ref R, path string) (unpin func() error, err error) {
+	err = withFdE(ref, func(fd int) error {
+		typ, err := typeOfFd(fd)
+		if err != nil {
+			return err
+		}
+		pinfd, err := unix.Open(path, unix.O_RDONLY|unix.O_CREAT|unix.O_EXCL|unix.O_CLOEXEC, 0o444)
+		if err != nil {
+			return fmt.Errorf("cannot create pin file %s, reason: %w", path, err)
+		}
+		_ = unix.Close(pinfd)
+		if err := unix.Mount("/proc/self/fd/"+strconv.Itoa(fd), path, "", unix.MS_BIND, ""); err != nil {
+			_ = os.Remove(path)
+			return fmt.Errorf("cannot pin %s namespace to %s, reason: %w", Name(typ), path, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return func() error {
+		return errors.Join(
+			unix.Unmount(path, unix.MNT_DETACH),
+			os.Remove(path))
+	}, nil
+}
@@ -0,0 +1,80 @@
+// Copyright 2025 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spacetest
+
+import (
+	"os"
+	"path/filepath"
+
+	"golang.org/x/sys/unix"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	. "github.com/thediveo/fdooze"
+)
+
+var _ = Describe("pinning namespaces", func() {
+
+	BeforeEach(func() {
+		if os.Getuid() != 0 {
+			Skip("needs root")
+		}
+		goodfds := Filedescriptors()
+		goodns := HeldNamespaces()
+		DeferCleanup(func() {
+			Expect(Filedescriptors()).NotTo(HaveLeakedFds(goodfds))
+			Expect(HeldNamespaces()).NotTo(HaveLeakedNamespaces(goodns))
+		})
+	})
+
+	It("pins and unpins a namespace referenced by fd", func() {
+		pinpath := filepath.Join(GinkgoT().TempDir(), "pin")
+		DeferCleanup(func() {
+			Expect(pinpath).NotTo(BeAnExistingFile())
+		})
+		netnsfd := NewTransient(unix.CLONE_NEWNET)
+		Pin(netnsfd, pinpath)
+		Expect(pinpath).To(BeSameNamespaceAs(netnsfd))
+		Expect(HeldNamespaces()).To(ContainElement(HaveField("Holder", "bind mount "+pinpath)))
+	})
+
+	It("pins namespaces referenced by path and by Namespace", func() {
+		dir := GinkgoT().TempDir()
+		netns := NewTransientNamespace(unix.CLONE_NEWNET)
+		Pin(netns, filepath.Join(dir, "handle"))
+		Pin(filepath.Join(dir, "handle"), filepath.Join(dir, "path"))
+		Expect(filepath.Join(dir, "path")).To(BeSameNamespaceAs(netns))
+	})
+
+	It("reports errors", func() {
+		dir := GinkgoT().TempDir()
+		Expect(PinE(-1, filepath.Join(dir, "pin"))).Error().To(
+			MatchError(ContainSubstring("cannot determine type of namespace")))
+		Expect(PinE("/nonexisting", filepath.Join(dir, "pin"))).Error().To(
+			MatchError(ContainSubstring(`cannot open namespace referenced as "/nonexisting"`)))
+		Expect(PinE("/proc/self/ns/net", filepath.Join(dir, "nonexisting", "pin"))).Error().To(
+			MatchError(ContainSubstring("cannot create pin file")))
+		Expect(filepath.Join(dir, "pin")).NotTo(BeAnExistingFile())
+	})
+
+	It("controls keeping pins of failed specs", func() {
+		DeferCleanup(func(keep bool) { keepPins.Store(keep) }, keepPins.Load())
+		Expect(KeepPinsOnFailure(true)).To(BeTrue())
+		Expect(keepPins.Load()).To(BeTrue())
+		KeepPinsOnFailure(false)
+		Expect(keepPins.Load()).To(BeFalse())
+	})
+
+})
@@ -0,0 +1,191 @@
+// Copyright 2025 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spacetest
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/thediveo/ioctl"
+	"golang.org/x/sys/unix"
+
+	. "github.com/onsi/ginkgo/v2" //nolint:staticcheck // ST1001 rule does not apply
+	. "github.com/onsi/gomega"    //nolint:staticcheck // ST1001 rule does not apply
+)
+
+// Linux kernel [ioctl(2)] command group for [pidfd namespace queries].
+//
+// [ioctl(2)]: https://man7.org/linux/man-pages/man2/ioctl.2.html
+// [pidfd namespace queries]: https://elixir.bootlin.com/linux/v6.11/source/include/uapi/linux/pidfd.h
+const _PIDFS_IOCTL_MAGIC = 0xff
+
+// pidfdGetNamespace maps namespace types to their pidfd ioctl requests
+// returning a file descriptor referencing the namespace of the type of the
+// process referenced by a pidfd.
+var pidfdGetNamespace = map[int]uint{
+	unix.CLONE_NEWCGROUP: ioctl.IO(_PIDFS_IOCTL_MAGIC, 1),
+	unix.CLONE_NEWIPC:    ioctl.IO(_PIDFS_IOCTL_MAGIC, 2),
+	unix.CLONE_NEWNS:     ioctl.IO(_PIDFS_IOCTL_MAGIC, 3),
+	unix.CLONE_NEWNET:    ioctl.IO(_PIDFS_IOCTL_MAGIC, 4),
+	unix.CLONE_NEWPID:    ioctl.IO(_PIDFS_IOCTL_MAGIC, 5),
+	unix.CLONE_NEWTIME:   ioctl.IO(_PIDFS_IOCTL_MAGIC, 7),
+	unix.CLONE_NEWUSER:   ioctl.IO(_PIDFS_IOCTL_MAGIC, 9),
+	unix.CLONE_NEWUTS:    ioctl.IO(_PIDFS_IOCTL_MAGIC, 10),
+}
+
+// FromPID returns a new [Namespace] for the namespace of the specified type of
+// the process with the specified PID. FromPID schedules a DeferCleanup of the
+// returned Namespace to be closed at the end of the current test.
+//
+// If the namespace cannot be determined, FromPID fails the current test.
+func FromPID(pid int, typ int) *Namespace {
+	GinkgoHelper()
+
+	ns, err := FromPIDE(pid, typ)
+	Expect(err).NotTo(HaveOccurred())
+	DeferCleanup(func() { _ = ns.Close() })
+	return ns
+}
+
+// FromPIDE works like [FromPID], but returns an error instead of failing the
+// current test. The caller is responsible for closing the returned Namespace.
+func FromPIDE(pid int, typ int) (*Namespace, error) {
+	pidfd, err := unix.PidfdOpen(pid, 0)
+	if err != nil {
+		return fromProcfs(pid, typ)
+	}
+	defer func() { _ = unix.Close(pidfd) }()
+	return FromPIDFdE(pidfd, typ)
+}
+
+// FromPIDFd returns a new [Namespace] for the namespace of the specified type
+// of the process referenced by the passed pidfd. FromPIDFd schedules a
+// DeferCleanup of the returned Namespace to be closed at the end of the current
+// test.
+//
+// On kernels supporting the PIDFD_GET_*_NAMESPACE ioctls (Linux 6.11+),
+// FromPIDFd directly asks the kernel for the namespace. On older kernels,
+// FromPIDFd falls back to procfs, checking that the process referenced by the
+// pidfd is still alive after having opened its namespace.
+//
+// In order to run code attached to several namespaces of a process, pass the
+// Namespaces returned by FromPIDFd to [Execute], for instance:
+//
+//	spacetest.Execute(func() {
+//	    // ...
+//	}, spacetest.FromPIDFd(pidfd, unix.CLONE_NEWNET),
+//	    spacetest.FromPIDFd(pidfd, unix.CLONE_NEWUTS))
+//
+// If the namespace cannot be determined, FromPIDFd fails the current test.
+func FromPIDFd(pidfd int, typ int) *Namespace {
+	GinkgoHelper()
+
+	ns, err := FromPIDFdE(pidfd, typ)
+	Expect(err).NotTo(HaveOccurred())
+	DeferCleanup(func() { _ = ns.Close() })
+	return ns
+}
+
+// FromPIDFdE works like [FromPIDFd], but returns an error instead of failing
+// the current test. The caller is responsible for closing the returned
+// Namespace.
+func FromPIDFdE(pidfd int, typ int) (*Namespace, error) {
+	request, ok := pidfdGetNamespace[typ]
+	if !ok {
+		return nil, fmt.Errorf("invalid namespace type %d", typ)
+	}
+	nsfd, err := ioctlRetFd(pidfd, request)
+	if errors.Is(err, unix.ENOTTY) {
+		return fromPIDFdProcfs(pidfd, typ)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot determine %s namespace of pidfd %d, reason: %w",
+			Name(typ), pidfd, err)
+	}
+	ns, err := NewNamespace(nsfd)
+	if err != nil {
+		_ = unix.Close(nsfd)
+		return nil, err
+	}
+	return ns, nil
+}
+
+// fromPIDFdProcfs returns a new Namespace for the namespace of the specified
+// type of the process referenced by the passed pidfd, using procfs instead of
+// pidfd ioctls.
+func fromPIDFdProcfs(pidfd int, typ int) (*Namespace, error) {
+	pid, err := pidOf(pidfd)
+	if err != nil {
+		return nil, err
+	}
+	ns, err := fromProcfs(pid, typ)
+	if err != nil {
+		return nil, err
+	}
+	// The PID might have been recycled in the meantime, so make sure that the
+	// process referenced by the pidfd is still alive, as then the PID still
+	// references the same process.
+	if err := unix.PidfdSendSignal(pidfd, 0, nil, 0); err != nil {
+		_ = ns.Close()
+		return nil, fmt.Errorf("process referenced by pidfd %d gone, reason: %w", pidfd, err)
+	}
+	return ns, nil
+}
+
+// fromProcfs returns a new Namespace for the namespace of the specified type of
+// the process with the specified PID, using procfs.
+func fromProcfs(pid int, typ int) (*Namespace, error) {
+	if _, ok := pidfdGetNamespace[typ]; !ok {
+		return nil, fmt.Errorf("invalid namespace type %d", typ)
+	}
+	nsfd, err := unix.Open("/proc/"+strconv.Itoa(pid)+"/ns/"+Name(typ), unix.O_RDONLY|unix.O_CLOEXEC, 0)
+	if err != nil {
+		return nil, fmt.Errorf("cannot open %s namespace of PID %d, reason: %w",
+			Name(typ), pid, err)
+	}
+	ns, err := NewNamespace(nsfd)
+	if err != nil {
+		_ = unix.Close(nsfd)
+		return nil, err
+	}
+	return ns, nil
+}
+
+// pidOf returns the PID of the process referenced by the passed pidfd, as seen
+// from the PID namespace of procfs.
+func pidOf(pidfd int) (int, error) {
+	fdinfo, err := os.ReadFile("/proc/self/fdinfo/" + strconv.Itoa(pidfd))
+	if err != nil {
+		return 0, fmt.Errorf("cannot determine PID of pidfd %d, reason: %w", pidfd, err)
+	}
+	for line := range strings.Lines(string(fdinfo)) {
+		value, ok := strings.CutPrefix(line, "Pid:")
+		if !ok {
+			continue
+		}
+		pid, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil {
+			return 0, fmt.Errorf("cannot determine PID of pidfd %d, reason: %w", pidfd, err)
+		}
+		if pid <= 0 {
+			return 0, fmt.Errorf("process referenced by pidfd %d gone or not visible", pidfd)
+		}
+		return pid, nil
+	}
+	return 0, fmt.Errorf("fd %d is not a pidfd", pidfd)
+}
@@ -0,0 +1,132 @@
+// Copyright 2025 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spacetest
+
+import (
+	"os"
+	"os/exec"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	. "github.com/thediveo/fdooze"
+	. "github.com/thediveo/success"
+)
+
+var _ = Describe("namespaces of processes", func() {
+
+	BeforeEach(func() {
+		goodfds := Filedescriptors()
+		DeferCleanup(func() {
+			Expect(Filedescriptors()).NotTo(HaveLeakedFds(goodfds))
+		})
+	})
+
+	// sleeper starts a child process in new namespaces of the specified types,
+	// returning its PID and a pidfd referencing it.
+	sleeper := func(types uintptr) (int, int) {
+		GinkgoHelper()
+		cmd := exec.Command("sleep", "60")
+		cmd.SysProcAttr = &syscall.SysProcAttr{Cloneflags: types}
+		Expect(cmd.Start()).To(Succeed())
+		DeferCleanup(func() {
+			_ = cmd.Process.Kill()
+			_ = cmd.Wait()
+		})
+		pidfd := Successful(unix.PidfdOpen(cmd.Process.Pid, 0))
+		DeferCleanup(func() { _ = unix.Close(pidfd) })
+		return cmd.Process.Pid, pidfd
+	}
+
+	It("returns the namespaces of our own process", func() {
+		netns := FromPID(os.Getpid(), unix.CLONE_NEWNET)
+		Expect(netns).To(BeSameNamespaceAs("/proc/self/ns/net"))
+
+		pidfd := Successful(unix.PidfdOpen(os.Getpid(), 0))
+		defer func() { _ = unix.Close(pidfd) }()
+		utsns := FromPIDFd(pidfd, unix.CLONE_NEWUTS)
+		Expect(utsns).To(BeSameNamespaceAs("/proc/self/ns/uts"))
+
+		ns := Successful(fromPIDFdProcfs(pidfd, unix.CLONE_NEWIPC))
+		defer func() { _ = ns.Close() }()
+		Expect(ns).To(BeSameNamespaceAs("/proc/self/ns/ipc"))
+	})
+
+	It("reports errors", func() {
+		Expect(FromPIDE(os.Getpid(), 0)).Error().To(MatchError("invalid namespace type 0"))
+		Expect(fromProcfs(os.Getpid(), 0)).Error().To(MatchError("invalid namespace type 0"))
+		Expect(fromProcfs(-1, unix.CLONE_NEWNET)).Error().To(
+			MatchError(ContainSubstring("cannot open net namespace of PID -1")))
+
+		fd := Successful(unix.Open(".", unix.O_RDONLY, 0))
+		defer func() { _ = unix.Close(fd) }()
+		Expect(pidOf(fd)).Error().To(MatchError(ContainSubstring("is not a pidfd")))
+		Expect(pidOf(-1)).Error().To(MatchError(ContainSubstring("cannot determine PID of pidfd -1")))
+	})
+
+	When("being root", func() {
+
+		BeforeEach(func() {
+			if os.Getuid() != 0 {
+				Skip("needs root")
+			}
+		})
+
+		It("returns the namespaces of another process", func() {
+			pid, pidfd := sleeper(unix.CLONE_NEWNET)
+			netns := FromPID(pid, unix.CLONE_NEWNET)
+			Expect(netns).NotTo(BeSameNamespaceAs("/proc/self/ns/net"))
+			Expect(FromPIDFd(pidfd, unix.CLONE_NEWNET)).To(BeSameNamespaceAs(netns))
+			Expect(FromPIDFd(pidfd, unix.CLONE_NEWUTS)).To(BeSameNamespaceAs("/proc/self/ns/uts"))
+		})
+
+		It("fails for terminated processes", func() {
+			cmd := exec.Command("true")
+			Expect(cmd.Start()).To(Succeed())
+			pidfd := Successful(unix.PidfdOpen(cmd.Process.Pid, 0))
+			defer func() { _ = unix.Close(pidfd) }()
+			Expect(cmd.Wait()).To(Succeed())
+
+			Expect(FromPIDFdE(pidfd, unix.CLONE_NEWNET)).Error().To(HaveOccurred())
+			Expect(fromPIDFdProcfs(pidfd, unix.CLONE_NEWNET)).Error().To(HaveOccurred())
+		})
+
+		It("executes in multiple namespaces of another process", func() {
+			pid, pidfd := sleeper(unix.CLONE_NEWNET | unix.CLONE_NEWUTS | unix.CLONE_NEWNS)
+			callersnetns := CurrentIno(unix.CLONE_NEWNET)
+			callersmntns := CurrentIno(unix.CLONE_NEWNS)
+			Execute(func() {
+				defer GinkgoRecover()
+				Expect(CurrentIno(unix.CLONE_NEWNET)).To(Equal(FromPID(pid, unix.CLONE_NEWNET).Ino()))
+				Expect(CurrentIno(unix.CLONE_NEWUTS)).To(Equal(FromPID(pid, unix.CLONE_NEWUTS).Ino()))
+				Expect(CurrentIno(unix.CLONE_NEWNS)).To(Equal(FromPID(pid, unix.CLONE_NEWNS).Ino()))
+			}, FromPIDFd(pidfd, unix.CLONE_NEWNET),
+				FromPIDFd(pidfd, unix.CLONE_NEWUTS),
+				FromPIDFd(pidfd, unix.CLONE_NEWNS))
+			Expect(CurrentIno(unix.CLONE_NEWNET)).To(Equal(callersnetns))
+			Expect(CurrentIno(unix.CLONE_NEWNS)).To(Equal(callersmntns))
+		})
+
+		It("refuses to execute in the user namespace of another process", func() {
+			_, pidfd := sleeper(0)
+			Expect(ExecuteE(func() {}, FromPIDFd(pidfd, unix.CLONE_NEWUSER))).To(
+				MatchError(ContainSubstring("cannot Execute() in different user namespace")))
+		})
+
+	})
+
+})
@@ -0,0 +1,214 @@
+// Copyright 2025 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spacetest
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"unsafe"
+
+	"github.com/thediveo/ioctl"
+	"golang.org/x/sys/unix"
+
+	. "github.com/onsi/ginkgo/v2" //nolint:staticcheck // ST1001 rule does not apply
+	. "github.com/onsi/gomega"    //nolint:staticcheck // ST1001 rule does not apply
+)
+
+// NS_GET_USERNS defines the ioctl request code that returns a file descriptor
+// referencing the user namespace owning the namespace referred to by a file
+// descriptor.
+var NS_GET_USERNS = ioctl.IO(_NSIO, 0x1) //nolint:godoclint // out of touch
+
+// NS_GET_PARENT defines the ioctl request code that returns a file descriptor
+// referencing the parent namespace of the hierarchical (PID or user) namespace
+// referred to by a file descriptor.
+var NS_GET_PARENT = ioctl.IO(_NSIO, 0x2) //nolint:godoclint // out of touch
+
+// NS_GET_OWNER_UID defines the ioctl request code that returns the UID of the
+// owner of the user namespace referred to by a file descriptor.
+var NS_GET_OWNER_UID = ioctl.IO(_NSIO, 0x4) //nolint:godoclint // out of touch
+
+// Owner returns the user namespace owning the namespace referenced either by a
+// file descriptor, a VFS path name, or a [Namespace]. For user namespaces,
+// Owner returns the parent user namespace, same as [Parent].
+//
+// Additionally, Owner schedules a DeferCleanup of the returned Namespace to be
+// closed at the end of the current test.
+//
+// If the specified reference is invalid or the owning user namespace is outside
+// the caller's user namespace, Owner fails the current test.
+func Owner[R Reference](ref R) *Namespace {
+	GinkgoHelper()
+
+	return relative(ref, NS_GET_USERNS, "owning user")
+}
+
+// Parent returns the parent namespace of the hierarchical namespace (that is,
+// a PID or user namespace) referenced either by a file descriptor, a VFS path
+// name, or a [Namespace].
+//
+// Additionally, Parent schedules a DeferCleanup of the returned Namespace to
+// be closed at the end of the current test.
+//
+// If the specified reference is invalid, not a hierarchical namespace, or the
+// parent namespace is outside the caller's namespace, Parent fails the
+// current test.
+func Parent[R Reference](ref R) *Namespace {
+	GinkgoHelper()
+
+	return relative(ref, NS_GET_PARENT, "parent")
+}
+
+// OwnerUID returns the UID of the creator of the user namespace referenced
+// either by a file descriptor, a VFS path name, or a [Namespace]. The UID is
+// in terms of the caller's user namespace.
+//
+// If the specified reference is invalid or not a user namespace, OwnerUID
+// fails the current test.
+func OwnerUID[R Reference](ref R) int {
+	GinkgoHelper()
+
+	var uid uint32
+	withFd(ref, func(fd int) {
+		_, _, errno := unix.Syscall(unix.SYS_IOCTL,
+			uintptr(fd), uintptr(NS_GET_OWNER_UID), uintptr(unsafe.Pointer(&uid)))
+		Expect(errno).To(BeZero(),
+			"cannot determine owner UID of user namespace")
+	})
+	return int(uid)
+}
+
+// Ancestors returns the chain of ancestor namespaces of the hierarchical
+// namespace (that is, a PID or user namespace) referenced either by a file
+// descriptor, a VFS path name, or a [Namespace]. The first element is the
+// parent namespace, the last element is the initial namespace or the
+// outermost ancestor namespace visible to the caller. If the referenced
+// namespace has no visible ancestors, Ancestors returns an empty slice.
+//
+// Additionally, Ancestors schedules a DeferCleanup of the returned Namespaces
+// to be closed at the end of the current test.
+//
+// If the specified reference is invalid or not a hierarchical namespace,
+// Ancestors fails the current test.
+func Ancestors[R Reference](ref R) []*Namespace {
+	GinkgoHelper()
+
+	ancestors := []*Namespace{}
+	withFd(ref, func(fd int) {
+		for {
+			parentfd, err := ioctlRetFd(fd, NS_GET_PARENT)
+			if errors.Is(err, unix.EPERM) {
+				// either we've reached the initial namespace or we've hit the
+				// boundary of what the caller is allowed to see.
+				return
+			}
+			Expect(err).NotTo(HaveOccurred(),
+				"cannot determine parent namespace")
+			parent, err := NewNamespace(parentfd)
+			if err != nil {
+				_ = unix.Close(parentfd)
+			}
+			Expect(err).NotTo(HaveOccurred())
+			DeferCleanup(func() { _ = parent.Close() })
+			ancestors = append(ancestors, parent)
+			fd = parent.Fd()
+		}
+	})
+	return ancestors
+}
+
+// relative returns the namespace related to the referenced namespace in the
+// way specified by the passed ioctl request, scheduling a DeferCleanup to
+// close the returned Namespace.
+func relative[R Reference](ref R, request uint, relation string) *Namespace {
+	GinkgoHelper()
+
+	var ns *Namespace
+	withFd(ref, func(fd int) {
+		relfd, err := ioctlRetFd(fd, request)
+		Expect(err).NotTo(HaveOccurred(),
+			"cannot determine %s namespace", relation)
+		ns, err = NewNamespace(relfd)
+		if err != nil {
+			_ = unix.Close(relfd)
+		}
+		Expect(err).NotTo(HaveOccurred())
+	})
+	DeferCleanup(func() { _ = ns.Close() })
+	return ns
+}
+
+// withFd calls fn with a file descriptor referencing the passed namespace
+// reference. In case of a VFS path name, withFd temporarily opens the path for
+// the duration of the fn call.
+func withFd[R Reference](ref R, fn func(fd int)) {
+	GinkgoHelper()
+
+	Expect(withFdE(ref, func(fd int) error {
+		fn(fd)
+		return nil
+	})).To(Succeed())
+}
+
+// withFdE works like [withFd], but returns an error instead of failing the
+// current test. Additionally, withFdE accepts an open [*os.File] as the
+// namespace reference, as needed by matchers. The error returned by fn is
+// passed through unchanged.
+func withFdE(ref any, fn func(fd int) error) error {
+	switch ref := ref.(type) {
+	case *Namespace:
+		if ref == nil {
+			return errors.New("expected a namespace reference, got nil *Namespace")
+		}
+		return fn(ref.Fd())
+	case *os.File:
+		if ref == nil {
+			return errors.New("expected a namespace reference, got nil *os.File")
+		}
+		return fn(int(ref.Fd()))
+	case string:
+		fd, err := unix.Open(ref, unix.O_RDONLY|unix.O_CLOEXEC, 0)
+		if err != nil {
+			return fmt.Errorf("cannot open namespace referenced as %q: %w", ref, err)
+		}
+		defer func() { _ = unix.Close(fd) }()
+		return fn(fd)
+	}
+	if v := reflect.ValueOf(ref); v.Kind() == reflect.String {
+		return withFdE(v.String(), fn)
+	}
+	fd, err := handleFd(ref)
+	if err != nil {
+		return fmt.Errorf(
+			"expected a namespace reference (file descriptor, path, *os.File, or *Namespace), got %T",
+			ref)
+	}
+	return fn(fd)
+}
+
+// ioctlRetFd issues the specified ioctl request and returns the successful
+// result as a file descriptor, or an error. In contrast to [ioctl.RetFd], the
+// error returned is the bare [unix.Errno], so callers can check for specific
+// errors.
+func ioctlRetFd(fd int, request uint) (int, error) {
+	retfd, _, errno := unix.Syscall(unix.SYS_IOCTL,
+		uintptr(fd), uintptr(request), 0)
+	if errno != 0 {
+		return -1, errno
+	}
+	return int(retfd), nil
+}
@@ -0,0 +1,69 @@
+// Copyright 2025 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spacetest
+
+import (
+	"golang.org/x/sys/unix"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	. "github.com/thediveo/fdooze"
+)
+
+var _ = Describe("namespace relations", func() {
+
+	BeforeEach(func() {
+		goodfds := Filedescriptors()
+		DeferCleanup(func() {
+			Eventually(Filedescriptors).ShouldNot(HaveLeakedFds(goodfds))
+		})
+	})
+
+	It("returns the owning user namespace", func() {
+		userIno := CurrentIno(unix.CLONE_NEWUSER)
+
+		owner := Owner("/proc/self/ns/net")
+		Expect(owner.Type()).To(Equal(unix.CLONE_NEWUSER))
+		Expect(owner.Ino()).To(Equal(userIno))
+
+		netns := CurrentNamespace(unix.CLONE_NEWNET)
+		Expect(Owner(netns).Ino()).To(Equal(userIno))
+		Expect(Owner(netns.Fd()).Ino()).To(Equal(userIno))
+	})
+
+	It("returns the owner UID of a user namespace", func() {
+		Expect(InterceptGomegaFailure(func() {
+			_ = OwnerUID("/proc/self/ns/user")
+		})).To(Succeed())
+		Expect(InterceptGomegaFailure(func() {
+			_ = OwnerUID("/proc/self/ns/net")
+		})).To(MatchError(ContainSubstring("cannot determine owner UID")))
+	})
+
+	It("rejects parents of non-hierarchical namespaces", func() {
+		Expect(InterceptGomegaFailure(func() {
+			_ = Parent("/proc/self/ns/net")
+		})).To(MatchError(ContainSubstring("cannot determine parent namespace")))
+		Expect(InterceptGomegaFailure(func() {
+			_ = Ancestors(CurrentNamespace(unix.CLONE_NEWNET))
+		})).To(MatchError(ContainSubstring("cannot determine parent namespace")))
+	})
+
+	It("stops walking the ancestry at the visible boundary", func() {
+		Expect(Ancestors("/proc/self/ns/user")).To(BeEmpty())
+		Expect(Ancestors(CurrentNamespace(unix.CLONE_NEWPID))).To(BeEmpty())
+	})
+
+})
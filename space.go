@@ -25,9 +25,9 @@ import (
 	. "github.com/onsi/gomega"    //nolint:staticcheck // ST1001 rule does not apply
 )
 
-// Reference is a Linux kernel namespace reference in VFS path textual form or
-// as an open file descriptor
-type Reference interface{ ~int | ~string }
+// Reference is a Linux kernel namespace reference in VFS path textual form, as
+// an open file descriptor, or as a [Namespace].
+type Reference interface{ ~int | ~string | *Namespace }
 
 // Linux kernel [ioctl(2)] command for [namespace relationship queries].
 //
@@ -47,13 +47,15 @@ func init() {
 }
 
 // Type returns the type constant for the Linux kernel namespace referenced
-// either by a file descriptor or a VFS path name.
+// either by a file descriptor, a VFS path name, or a [Namespace].
 //
 // If the specified reference is invalid, Type fails the current test.
 func Type[R Reference](ref R) int {
 	GinkgoHelper()
 
 	switch ref := any(ref).(type) {
+	case *Namespace:
+		return ref.Type()
 	case int:
 		typ, err := unix.IoctlRetInt(ref, NS_GET_NSTYPE)
 		Expect(err).NotTo(HaveOccurred(),
@@ -73,7 +75,8 @@ func Type[R Reference](ref R) int {
 }
 
 // Ino returns the identification (inode number) of the passed Linux kernel
-// namespace that is either referenced by a file descriptor or a VFS path name.
+// namespace that is either referenced by a file descriptor, a VFS path name, or
+// a [Namespace].
 //
 // If the specified reference is invalid or doesn't match the passed type of
 // namespace, Ino fails the current test.
@@ -82,6 +85,10 @@ func Ino[R Reference](ref R, typ int) uint64 {
 
 	var namespaceStat unix.Stat_t
 	switch ref := any(ref).(type) {
+	case *Namespace:
+		Expect(ref.Type()).To(Equal(typ),
+			"not a %s namespace", Name(typ))
+		return ref.Ino()
 	case int:
 		Expect(unix.Fstat(ref, &namespaceStat)).To(Succeed(),
 			func() string {
@@ -108,19 +115,41 @@ func Ino[R Reference](ref R, typ int) uint64 {
 // leaking it.
 //
 // If the specified typ of namespace is unknown, Current fails the current test.
+//
+// Current is a thin wrapper around [CurrentNamespace], returning the file
+// descriptor of the Namespace.
 func Current(typ int) int {
 	GinkgoHelper()
 
+	return CurrentNamespace(typ).Fd()
+}
+
+// CurrentNamespace returns a [Namespace] referencing the calling OS-level
+// thread's current namespace of type “typ”. Please note that the caller's go
+// routine should be thread-locked.
+//
+// Additionally, CurrentNamespace schedules a DeferCleanup of the returned
+// Namespace to be closed at the end of the current test in order to avoid
+// leaking it.
+//
+// If the specified typ of namespace is unknown, CurrentNamespace fails the
+// current test.
+func CurrentNamespace(typ int) *Namespace {
+	GinkgoHelper()
+
 	typename := Name(typ)
 	Expect(typename).NotTo(BeEmpty(),
 		"unknown type of namespace %d", typ)
 	nsfd, err := unix.Open("/proc/thread-self/ns/"+typename, unix.O_RDONLY, 0)
 	Expect(err).NotTo(HaveOccurred(),
 		"cannot determine current %s namespace from procfs", typename)
-	DeferCleanup(func() {
+	ns, err := NewNamespace(nsfd)
+	if err != nil {
 		_ = unix.Close(nsfd)
-	})
-	return nsfd
+	}
+	Expect(err).NotTo(HaveOccurred())
+	DeferCleanup(func() { _ = ns.Close() })
+	return ns
 }
 
 // CurrentIno returns the identification (inode number) for the namespace (of
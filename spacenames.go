@@ -38,3 +38,14 @@ var spaceName = map[int]string{
 	unix.CLONE_NEWUSER:   "user",
 	unix.CLONE_NEWUTS:    "uts",
 }
+
+// typeOf returns the type of Linux kernel namespace for the specified name, as
+// used in /proc/$PID/ns, or false if not known.
+func typeOf(name string) (int, bool) {
+	for typ, typename := range spaceName {
+		if typename == name {
+			return typ, true
+		}
+	}
+	return 0, false
+}
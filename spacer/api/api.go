@@ -46,6 +46,8 @@ func init() {
 	gob.Register(&SubspaceResponse{})
 	gob.Register(&RoomsRequest{})
 	gob.Register(&RoomsResponse{})
+	gob.Register(&ExecRequest{})
+	gob.Register(&ExecResponse{})
 }
 
 type UnhandlebarRequest struct{}
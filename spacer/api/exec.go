@@ -0,0 +1,119 @@
+// Copyright 2025 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import "golang.org/x/sys/unix"
+
+// ExecRequest requests the spacer service to start the specified executable
+// as a child process of the spacer service, so that this child process runs
+// inside the user and PID namespaces of the spacer service. The child process
+// inherits the environment of the spacer service, extended by the environment
+// variables in Env (in “key=value” form).
+type ExecRequest struct {
+	Path string
+	Args []string
+	Env  []string
+}
+
+// ExecResponse returns the connected unix domain socket to talk to the child
+// process started by the spacer service, as well as the PID fd of the child
+// process. The child process finds its end of the connected unix domain socket
+// as fd 3.
+//
+// Please note that the receiver takes ownership of the returned file
+// descriptors and thus is responsible to close them when not needing them
+// anymore.
+type ExecResponse struct {
+	Conn  int // fd of client unix domain socket
+	PIDFd int // PID fd for the child process
+}
+
+var _ Request = (*ExecRequest)(nil)
+
+func (e ExecRequest) request() {}
+
+var (
+	_ Response   = (*ExecResponse)(nil)
+	_ FdsEncoder = (*ExecResponse)(nil)
+	_ FdsDecoder = (*ExecResponse)(nil)
+)
+
+func (e ExecResponse) response() {}
+
+// EncodeFds returns the file descriptors contained in the response message,
+// replacing the original message fields with zero values so the fields don't
+// get transferred by gob.
+func (e *ExecResponse) EncodeFds() []int {
+	return auxiliaryFds(nil).
+		borrow(&e.Conn).
+		borrow(&e.PIDFd)
+}
+
+// DecodeFds distributes the passed file descriptors that were received as
+// auxiliary data with a response message back into their corresponding message
+// fields. DecodeFds closes any passed file descriptors it cannot make any sense
+// of.
+func (e *ExecResponse) DecodeFds(fds []int) {
+	for idx, fd := range fds {
+		switch idx {
+		case 0:
+			e.Conn = fd
+		case 1:
+			e.PIDFd = fd
+		default:
+			_ = unix.Close(fd)
+		}
+	}
+}
+
+// CallRequest is sent directly to a child process started by an
+// [ExecRequest], asking it to run the registered function of the specified
+// name while being attached to the namespaces referenced by the Nsfds file
+// descriptors. The file descriptors are transferred out-of-band.
+type CallRequest struct {
+	Name  string
+	Nsfds []int
+}
+
+var (
+	_ FdsEncoder = (*CallRequest)(nil)
+	_ FdsDecoder = (*CallRequest)(nil)
+)
+
+// EncodeFds returns the namespace file descriptors contained in the request
+// message, replacing the original message field with nil so the fds don't get
+// transferred by gob.
+func (c *CallRequest) EncodeFds() []int {
+	fds := c.Nsfds
+	c.Nsfds = nil
+	return fds
+}
+
+// DecodeFds stores the passed file descriptors that were received as
+// auxiliary data with a request message in the Nsfds field.
+func (c *CallRequest) DecodeFds(fds []int) {
+	c.Nsfds = fds
+}
+
+// CallResponse returns the outcome of running a registered function in a
+// child process: either the gob-encoded Result, a Gomega Failure message, a
+// Panic description, or an Error message when the child process couldn't run
+// the function in the first place.
+type CallResponse struct {
+	Result  []byte
+	Failure string
+	Panic   string
+	Error   string
+}
@@ -0,0 +1,60 @@
+// Copyright 2025 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"golang.org/x/sys/unix"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	. "github.com/thediveo/fdooze"
+	. "github.com/thediveo/success"
+)
+
+var _ = Describe("exec", func() {
+
+	BeforeEach(func() {
+		goodfds := Filedescriptors()
+		DeferCleanup(func() {
+			Expect(Filedescriptors()).NotTo(HaveLeakedFds(goodfds))
+		})
+	})
+
+	It("transfers exec response fds out-of-band", func() {
+		fd1 := Successful(unix.Open(".", unix.O_RDONLY, 0))
+		fd2 := Successful(unix.Open(".", unix.O_RDONLY, 0))
+		defer func() { _ = unix.Close(fd1); _ = unix.Close(fd2) }()
+		resp := &ExecResponse{
+			Conn:  fd1,
+			PIDFd: fd2,
+		}
+		fds := resp.EncodeFds()
+		Expect(fds).To(ConsistOf(fd1, fd2))
+		Expect(*resp).To(BeZero())
+		resp.DecodeFds(fds)
+		Expect(resp.Conn).To(Equal(fd1))
+		Expect(resp.PIDFd).To(Equal(fd2))
+	})
+
+	It("transfers call request fds out-of-band", func() {
+		req := &CallRequest{Name: "foo", Nsfds: []int{42, 666}}
+		fds := req.EncodeFds()
+		Expect(fds).To(Equal([]int{42, 666}))
+		Expect(req.Nsfds).To(BeNil())
+		req.DecodeFds(fds)
+		Expect(req.Nsfds).To(Equal([]int{42, 666}))
+	})
+
+})
@@ -16,15 +16,17 @@ package spacer
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"io"
+	"slices"
 	"sync"
 	"time"
 
 	gi "github.com/onsi/ginkgo/v2"
 	g "github.com/onsi/gomega"
-	"github.com/onsi/gomega/gcustom"
 	"github.com/onsi/gomega/gexec"
-	"github.com/onsi/gomega/types"
+	"github.com/thediveo/spacetest"
 	"github.com/thediveo/spacetest/spacer/api"
 	"github.com/thediveo/spacetest/spacer/gobmsg"
 	"github.com/thediveo/spacetest/spacer/service"
@@ -35,16 +37,24 @@ import (
 // Client connects to exactly one spacer service instance, which might be
 // in-process or a separate process.
 //
+// The Client methods come in two flavors: the Ginkgo-oriented methods, such as
+// [Client.Subspace] and [Client.NewTransient], fail the current test in case
+// of errors and automatically schedule closing the namespace file descriptors
+// returned. In contrast, the error-returning methods, such as
+// [Client.SubspaceE] and [Client.NewTransientE], can be used outside Ginkgo
+// tests and leave closing the returned file descriptors to their callers.
+//
 // # Important
 //
 // Client cannot(!) be used concurrently.
 type Client struct {
-	conn   *uds.Conn
-	enc    *gobmsg.Encoder
-	dec    *gobmsg.Decoder
-	stdout io.Writer
-	stderr io.Writer
-	pid    int
+	conn       *uds.Conn
+	enc        *gobmsg.Encoder
+	dec        *gobmsg.Decoder
+	stdout     io.Writer
+	stderr     io.Writer
+	pid        int
+	servicebin string
 }
 
 var (
@@ -52,22 +62,29 @@ var (
 	spacerServiceBinary string
 )
 
-func spacerServicePath() string {
+// spacerServicePath returns the path of the spacer service binary, building it
+// first if necessary. If building is needed, spacerServicePath first calls the
+// building func, if non-nil.
+func spacerServicePath(building func()) (string, error) {
 	spacerbinarymu.Lock()
 	defer spacerbinarymu.Unlock()
 
 	if spacerServiceBinary != "" {
-		return spacerServiceBinary
+		return spacerServiceBinary, nil
 	}
 
-	gi.By("building the spacer service binary")
+	if building != nil {
+		building()
+	}
 	var err error
 	spacerServiceBinary, err = gexec.BuildWithEnvironment(
 		"github.com/thediveo/spacetest/spacer/service/cmd/spacer-service",
 		[]string{"CGO_ENABLED=0"},
 		"-tags=usergo,netgo")
-	g.Expect(err).NotTo(g.HaveOccurred(), "cannot build spacer service binary")
-	return spacerServiceBinary
+	if err != nil {
+		return "", fmt.Errorf("cannot build spacer service binary: %w", err)
+	}
+	return spacerServiceBinary, nil
 }
 
 // New returns a new client connected to a new spacer service instance. This
@@ -78,15 +95,45 @@ func spacerServicePath() string {
 func New(ctx context.Context, opts ...Option) *Client {
 	gi.GinkgoHelper()
 
+	c, err := newClient(ctx, func() { gi.By("building the spacer service binary") }, opts...)
+	g.Expect(err).NotTo(g.HaveOccurred())
+	return c
+}
+
+// NewE works like [New], but returns an error instead of failing the current
+// test. NewE thus can also be used outside Ginkgo tests.
+//
+// Unless the spacer service binary has been specified using
+// [WithServiceBinary], NewE builds the spacer service binary; in this case,
+// make sure to call [gexec.CleanupBuildArtifacts] when done.
+func NewE(ctx context.Context, opts ...Option) (*Client, error) {
+	return newClient(ctx, nil, opts...)
+}
+
+// newClient returns a new client connected to a new spacer service instance,
+// or an error. If the spacer service binary needs to be built first, then
+// newClient calls the building func, if non-nil.
+func newClient(ctx context.Context, building func(), opts ...Option) (*Client, error) {
 	c := &Client{}
 	for _, opt := range opts {
-		g.Expect(opt(c)).To(g.Succeed(), "cannot apply option")
+		if err := opt(c); err != nil {
+			return nil, fmt.Errorf("cannot apply option: %w", err)
+		}
 	}
 
-	servicebinpath := spacerServicePath()
+	servicebinpath := c.servicebin
+	if servicebinpath == "" {
+		var err error
+		servicebinpath, err = spacerServicePath(building)
+		if err != nil {
+			return nil, err
+		}
+	}
 
 	dupond, dupont, err := uds.NewPair()
-	g.Expect(err).NotTo(g.HaveOccurred(), "cannot create connected unix domain socket pair")
+	if err != nil {
+		return nil, fmt.Errorf("cannot create connected unix domain socket pair: %w", err)
+	}
 
 	go func() {
 		service.Serve(ctx, dupont, &service.Spacemaker{
@@ -100,7 +147,7 @@ func New(ctx context.Context, opts ...Option) *Client {
 	c.conn = dupond
 	c.enc = gobmsg.NewEncoder()
 	c.dec = gobmsg.NewDecoder()
-	return c
+	return c, nil
 }
 
 // Close the connection to the spacer service instance. This will cause the
@@ -142,37 +189,67 @@ func (c *Client) PID() int {
 func (c *Client) Subspace(user, pid bool) (*Client, api.Subspaces) {
 	gi.GinkgoHelper()
 
-	resp := do[*api.SubspaceResponse](c, api.SubspaceRequest{
+	newclient, subspaces, err := c.SubspaceE(user, pid)
+	g.Expect(err).NotTo(g.HaveOccurred())
+
+	gi.DeferCleanup(func(userfd, pidfd int) {
+		if pidfd > 0 {
+			_ = unix.Close(pidfd)
+		}
+		if userfd > 0 {
+			_ = unix.Close(userfd)
+		}
+	}, subspaces.User, subspaces.PID)
+
+	return newclient, subspaces
+}
+
+// SubspaceE works like [Client.Subspace], but returns an error instead of
+// failing the current test. SubspaceE thus can also be used outside Ginkgo
+// tests. The caller is responsible for closing the file descriptors of the
+// namespaces returned.
+func (c *Client) SubspaceE(user, pid bool) (*Client, api.Subspaces, error) {
+	resp, err := doE[*api.SubspaceResponse](c, api.SubspaceRequest{
 		Spaces: uint64(namespaces(0).ifrequested(user, unix.CLONE_NEWUSER).
 			ifrequested(pid, unix.CLONE_NEWPID)),
 	}, "subspace")
+	if err != nil {
+		return nil, api.Subspaces{}, err
+	}
 	defer func() { _ = unix.Close(resp.PIDFd) }()
 
+	closeSpaces := func() {
+		if resp.PID > 0 {
+			_ = unix.Close(resp.PID)
+		}
+		if resp.User > 0 {
+			_ = unix.Close(resp.User)
+		}
+	}
+
 	subconn, err := uds.NewUnixConn(resp.Conn, "subspace")
-	g.Expect(err).NotTo(g.HaveOccurred(), "subspace connection failure")
+	if err != nil {
+		closeSpaces()
+		return nil, api.Subspaces{}, fmt.Errorf("subspace connection failure: %w", err)
+	}
 
 	subspacerPID, err := PIDfromPIDFd(resp.PIDFd)
-	g.Expect(err).NotTo(g.HaveOccurred(), "can't determine subspace service PID")
+	if err != nil {
+		_ = subconn.Close()
+		closeSpaces()
+		return nil, api.Subspaces{}, fmt.Errorf("can't determine subspace service PID: %w", err)
+	}
 
 	newclient := &Client{
-		conn:   subconn,
-		enc:    gobmsg.NewEncoder(),
-		dec:    gobmsg.NewDecoder(),
-		stdout: c.stdout,
-		stderr: c.stderr,
-		pid:    subspacerPID,
+		conn:       subconn,
+		enc:        gobmsg.NewEncoder(),
+		dec:        gobmsg.NewDecoder(),
+		stdout:     c.stdout,
+		stderr:     c.stderr,
+		pid:        subspacerPID,
+		servicebin: c.servicebin,
 	}
-
-	gi.DeferCleanup(func(userfd, pidfd int) {
-		if pidfd > 0 {
-			_ = unix.Close(pidfd)
-		}
-		if userfd > 0 {
-			_ = unix.Close(userfd)
-		}
-	}, resp.User, resp.PID)
-
-	return newclient, resp.Subspaces
+	return newclient, resp.Subspaces, nil
 }
 
 // NewTransient creates a new Linux kernel namespace of the specified type using
@@ -194,28 +271,62 @@ func (c *Client) Subspace(user, pid bool) (*Client, api.Subspaces) {
 func (c *Client) NewTransient(typ int) int {
 	gi.GinkgoHelper()
 
+	nsfd, err := c.NewTransientE(typ)
+	g.Expect(err).NotTo(g.HaveOccurred())
+	gi.DeferCleanup(func() { _ = unix.Close(nsfd) })
+	return nsfd
+}
+
+// NewTransientE works like [Client.NewTransient], but returns an error instead
+// of failing the current test. NewTransientE thus can also be used outside
+// Ginkgo tests. The caller is responsible for closing the returned file
+// descriptor.
+func (c *Client) NewTransientE(typ int) (int, error) {
+	if !slices.Contains([]int{
+		unix.CLONE_NEWCGROUP,
+		unix.CLONE_NEWIPC,
+		unix.CLONE_NEWNS,
+		unix.CLONE_NEWNET,
+		unix.CLONE_NEWTIME,
+		unix.CLONE_NEWUTS,
+	}, typ) {
+		return -1, errors.New("invalid namespace type")
+	}
+	rooms, err := c.RoomsE(
+		typ == unix.CLONE_NEWCGROUP,
+		typ == unix.CLONE_NEWIPC,
+		typ == unix.CLONE_NEWNS,
+		typ == unix.CLONE_NEWNET,
+		typ == unix.CLONE_NEWTIME,
+		typ == unix.CLONE_NEWUTS)
+	if err != nil {
+		return -1, err
+	}
 	switch typ {
 	case unix.CLONE_NEWCGROUP:
-		return c.Rooms(true, false, false, false, false, false).Cgroup
+		return rooms.Cgroup, nil
 	case unix.CLONE_NEWIPC:
-		return c.Rooms(false, true, false, false, false, false).IPC
+		return rooms.IPC, nil
 	case unix.CLONE_NEWNS:
-		return c.Rooms(false, false, true, false, false, false).Mnt
+		return rooms.Mnt, nil
 	case unix.CLONE_NEWNET:
-		return c.Rooms(false, false, false, true, false, false).Net
+		return rooms.Net, nil
 	case unix.CLONE_NEWTIME:
-		return c.Rooms(false, false, false, false, true, false).Time
-	case unix.CLONE_NEWUTS:
-		return c.Rooms(false, false, false, false, false, true).UTS
+		return rooms.Time, nil
+	default:
+		return rooms.UTS, nil
 	}
-	g.Expect(typ).To(beInvalid())
-	return -1 // never reached
 }
 
-func beInvalid() types.GomegaMatcher {
-	return gcustom.MakeMatcher(func(int) (bool, error) {
-		return true, nil
-	}).WithMessage("invalid namespace type")
+// NewTransientNamespace works like [Client.NewTransient], but returns a
+// [spacetest.Namespace] instead of a bare file descriptor. The returned
+// Namespace is automatically closed at the end of the current test.
+func (c *Client) NewTransientNamespace(typ int) *spacetest.Namespace {
+	gi.GinkgoHelper()
+
+	// NewTransient already schedules closing the fd it returns, so we need to
+	// work on our own copy.
+	return spacetest.OpenNamespace(c.NewTransient(typ))
 }
 
 // Rooms returns new namespaces of the requested type(s). The namespaces are
@@ -234,7 +345,20 @@ func beInvalid() types.GomegaMatcher {
 func (c *Client) Rooms(cgroup, ipc, mnt, net, time, uts bool) api.RoomsResponse {
 	gi.GinkgoHelper()
 
-	resp := do[*api.RoomsResponse](c, api.RoomsRequest{
+	resp, err := c.RoomsE(cgroup, ipc, mnt, net, time, uts)
+	g.Expect(err).NotTo(g.HaveOccurred())
+
+	gi.DeferCleanup(func() { closeRooms(resp) })
+
+	return resp
+}
+
+// RoomsE works like [Client.Rooms], but returns an error instead of failing
+// the current test. RoomsE thus can also be used outside Ginkgo tests. The
+// caller is responsible for closing the file descriptors of the namespaces
+// returned.
+func (c *Client) RoomsE(cgroup, ipc, mnt, net, time, uts bool) (api.RoomsResponse, error) {
+	resp, err := doE[*api.RoomsResponse](c, api.RoomsRequest{
 		Spaces: uint64(namespaces(0).ifrequested(cgroup, unix.CLONE_NEWCGROUP).
 			ifrequested(ipc, unix.CLONE_NEWIPC).
 			ifrequested(mnt, unix.CLONE_NEWNS).
@@ -242,29 +366,20 @@ func (c *Client) Rooms(cgroup, ipc, mnt, net, time, uts bool) api.RoomsResponse
 			ifrequested(time, unix.CLONE_NEWTIME).
 			ifrequested(uts, unix.CLONE_NEWUTS)),
 	}, "rooms")
+	if err != nil {
+		return api.RoomsResponse{}, err
+	}
+	return *resp, nil
+}
 
-	gi.DeferCleanup(func(cgroupfd, ipcfd, mntfd, netfd, timefd, utsfd int) {
-		if cgroupfd > 0 {
-			_ = unix.Close(cgroupfd)
-		}
-		if ipcfd > 0 {
-			_ = unix.Close(ipcfd)
-		}
-		if mntfd > 0 {
-			_ = unix.Close(mntfd)
-		}
-		if netfd > 0 {
-			_ = unix.Close(netfd)
+// closeRooms closes the namespace file descriptors in the passed rooms
+// response.
+func closeRooms(resp api.RoomsResponse) {
+	for _, fd := range []int{resp.Cgroup, resp.IPC, resp.Mnt, resp.Net, resp.Time, resp.UTS} {
+		if fd > 0 {
+			_ = unix.Close(fd)
 		}
-		if timefd > 0 {
-			_ = unix.Close(timefd)
-		}
-		if utsfd > 0 {
-			_ = unix.Close(utsfd)
-		}
-	}, resp.Cgroup, resp.IPC, resp.Mnt, resp.Net, resp.Time, resp.UTS)
-
-	return *resp
+	}
 }
 
 type namespaces uint64
@@ -276,41 +391,60 @@ func (n namespaces) ifrequested(b bool, flag uint64) namespaces {
 	return n | namespaces(flag)
 }
 
-// do the passed API request, returning a non-failure API response; or otherwise
-// failing the current test.
-func (c *Client) do(req api.Request, name string) api.Response {
-	gi.GinkgoHelper()
-
+// doE does the passed API request, returning a non-failure API response; or
+// otherwise an error.
+func (c *Client) doE(req api.Request, name string) (api.Response, error) {
 	msg, err := c.enc.Encode(&req)
-	g.Expect(err).NotTo(g.HaveOccurred(), "cannot encode %s request", name)
-	g.Expect(c.conn.SendWithFds(msg)).Error().NotTo(g.HaveOccurred(),
-		"cannot send %s request", name)
+	if err != nil {
+		return nil, fmt.Errorf("cannot encode %s request: %w", name, err)
+	}
+	if _, err := c.conn.SendWithFds(msg); err != nil {
+		return nil, fmt.Errorf("cannot send %s request: %w", name, err)
+	}
 
-	g.Expect(c.conn.SetReadDeadline(time.Now().Add(5*time.Second))).To(g.Succeed(),
-		"cannot receive %s response", name)
+	if err := c.conn.SetReadDeadline(time.Now().Add(5 * time.Second)); err != nil {
+		return nil, fmt.Errorf("cannot receive %s response: %w", name, err)
+	}
 	n, fds, err := c.conn.ReceiveWithFds(c.dec.Buffer(), 3)
-	g.Expect(err).NotTo(g.HaveOccurred(), "cannot receive %s response", name)
+	if err != nil {
+		return nil, fmt.Errorf("cannot receive %s response: %w", name, err)
+	}
+	closeFds := func() {
+		for _, fd := range fds {
+			_ = unix.Close(fd)
+		}
+	}
 
 	var resp api.Response
-	g.Expect(c.dec.Decode(n, &resp)).To(g.Succeed(),
-		"cannot decode %s response", name)
-	g.Expect(resp).NotTo(api.HaveFailed(), "%s service failed", name)
+	if err := c.dec.Decode(n, &resp); err != nil {
+		closeFds()
+		return nil, fmt.Errorf("cannot decode %s response: %w", name, err)
+	}
+	if errresp, ok := resp.(*api.ErrorResponse); ok {
+		closeFds()
+		return nil, fmt.Errorf("%s service failed: %s", name, errresp.Reason)
+	}
 	if r, ok := resp.(api.FdsDecoder); ok {
 		r.DecodeFds(fds)
-	} else {
-		g.Expect(fds).To(g.BeEmpty(),
-			"%s service received fds when it shouldn't; response: %T", name, resp)
+	} else if len(fds) != 0 {
+		closeFds()
+		return nil, fmt.Errorf("%s service received fds when it shouldn't; response: %T",
+			name, resp)
 	}
-	return resp
+	return resp, nil
 }
 
-// do the passed API request on the specified client, returning a response of
-// type R, or otherwise failing the current test.
-func do[R any](c *Client, req api.Request, name string) R {
-	gi.GinkgoHelper()
-
-	resp := c.do(req, name)
+// doE does the passed API request on the specified client, returning a
+// response of type R, or otherwise an error.
+func doE[R any](c *Client, req api.Request, name string) (R, error) {
+	var zero R
+	resp, err := c.doE(req, name)
+	if err != nil {
+		return zero, err
+	}
 	r, ok := resp.(R)
-	g.Expect(ok).To(g.BeTrue(), "not a %s response", name)
-	return r
+	if !ok {
+		return zero, fmt.Errorf("not a %s response", name)
+	}
+	return r, nil
 }
@@ -38,10 +38,12 @@ var _ = Describe("spacer client", func() {
 	BeforeEach(func() {
 		goodfds := Filedescriptors()
 		goodgos := Goroutines()
+		goodns := spacetest.HeldNamespaces()
 		DeferCleanup(func() {
 			Eventually(Goroutines).Within(2 * time.Second).ProbeEvery(100 * time.Millisecond).
 				ShouldNot(HaveLeaked(goodgos))
 			Expect(Filedescriptors()).NotTo(HaveLeakedFds(goodfds))
+			Eventually(spacetest.HeldNamespaces).ShouldNot(spacetest.HaveLeakedNamespaces(goodns))
 		})
 	})
 
@@ -68,6 +70,26 @@ var _ = Describe("spacer client", func() {
 			Expect(nsfd).To(BeNumerically(">", 0))
 		})
 
+		It("returns errors instead of failing", func(ctx context.Context) {
+			cl := Successful(NewE(ctx, WithOut(GinkgoWriter), WithErr(GinkgoWriter)))
+			defer cl.Close()
+
+			subcl, spc, err := cl.SubspaceE(true, false)
+			Expect(err).NotTo(HaveOccurred())
+			defer subcl.Close()
+			defer func() { _ = unix.Close(spc.User) }()
+			Expect(spc.PID).To(BeZero())
+
+			nsfd, err := subcl.NewTransientE(unix.CLONE_NEWPID)
+			Expect(err).To(MatchError("invalid namespace type"))
+			Expect(nsfd).To(Equal(-1))
+
+			nsfd = Successful(subcl.NewTransientE(unix.CLONE_NEWUTS))
+			defer func() { _ = unix.Close(nsfd) }()
+			Expect(nsfd).To(spacetest.BeNamespaceOfType(unix.CLONE_NEWUTS))
+			Expect(nsfd).To(spacetest.BeOwnedByUserNamespace(spc.User))
+		})
+
 	})
 
 	When("working with the spacer service as root", func() {
@@ -115,6 +137,8 @@ var _ = Describe("spacer client", func() {
 				defer cl.Close()
 				nsfd := cl.NewTransient(typ)
 				Expect(nsfd).To(BeNumerically(">", 0))
+				ns := cl.NewTransientNamespace(typ)
+				Expect(ns.Type()).To(Equal(typ))
 			},
 			Entry("cgroup", unix.CLONE_NEWCGROUP),
 			Entry("ipc", unix.CLONE_NEWIPC),
@@ -188,6 +212,19 @@ var _ = Describe("spacer client", func() {
 				Equal(spacetest.CurrentIno(unix.CLONE_NEWUSER)))
 		})
 
+		It("walks the user namespace ancestry", func() {
+			owner := spacetest.Owner(netnsfd)
+			Expect(owner.Ino()).To(Equal(spacetest.Ino(childusernsfd, unix.CLONE_NEWUSER)))
+			Expect(spacetest.OwnerUID(owner)).To(Equal(os.Geteuid()))
+			Expect(spacetest.Parent(owner).Ino()).To(
+				Equal(spacetest.CurrentIno(unix.CLONE_NEWUSER)))
+			ancestors := spacetest.Ancestors(owner)
+			Expect(ancestors).NotTo(BeEmpty())
+			Expect(ancestors[0].Ino()).To(Equal(spacetest.CurrentIno(unix.CLONE_NEWUSER)))
+			Expect(childusernsfd).To(spacetest.BeChildNamespaceOf("/proc/self/ns/user"))
+			Expect(netnsfd).To(spacetest.BeOwnedByUserNamespace(childusernsfd))
+		})
+
 	})
 
 	It("returns different subspace service PIDs", func(ctx context.Context) {
@@ -20,6 +20,16 @@ around with especially the [unshare(1)] command, avoiding juggling and dropping
 the many unshare CLI flags, as well as the tedious and brittle passing of
 namespace information back into the Go test code.
 
+# Running Functions in Child Processes
+
+Go programs cannot switch into user namespaces other than their own. Instead,
+[ExecuteInChild] runs a function that has been registered using [Register] in a
+new child process of a spacer service, so that the function runs inside the
+user and PID namespaces of the spacer service, and optionally also attached to
+further namespaces. The child process is a fresh instance of the test binary,
+so the test binary needs to call [RunRegistered] in its TestMain. The result of
+the function as well as any Gomega failure get passed back to the caller.
+
 # Important
 
 Make sure to call [gexec.CleanupBuildArtefacts] in your AfterSuite when using
@@ -0,0 +1,339 @@
+// Copyright 2025 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spacer
+
+import (
+	"bytes"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"os"
+	"runtime"
+	"strconv"
+	"sync"
+
+	gi "github.com/onsi/ginkgo/v2"
+	g "github.com/onsi/gomega"
+	"github.com/thediveo/spacetest"
+	"github.com/thediveo/spacetest/spacer/api"
+	"github.com/thediveo/spacetest/spacer/gobmsg"
+	"github.com/thediveo/spacetest/uds"
+	"golang.org/x/sys/unix"
+)
+
+// childEnv is the name of the environment variable marking a process as a
+// child process started by [ExecuteInChild] (or [ExecuteInChildE]) in order to
+// run a registered function.
+const childEnv = "SPACETEST_SPACER_CHILD"
+
+// maxCallFds is the maximum number of namespace file descriptors that can be
+// passed to a registered function in a child process.
+const maxCallFds = 16
+
+var (
+	registrymu sync.Mutex
+	registry   = map[string]func() ([]byte, error){}
+)
+
+// Register registers the passed function fn under the specified name, so that
+// it can later be run by [ExecuteInChild] in a child process of a spacer
+// service. The result of fn must be gob-encodable.
+//
+// Register must be called from package-level variable declarations or init
+// functions, so that the child process (which is a fresh instance of the test
+// binary) registers the same functions as the parent process did. Register
+// panics if a function of the same name has already been registered.
+func Register[T any](name string, fn func() T) {
+	registrymu.Lock()
+	defer registrymu.Unlock()
+	if _, ok := registry[name]; ok {
+		panic("spacer: function " + strconv.Quote(name) + " already registered")
+	}
+	registry[name] = func() ([]byte, error) {
+		var buff bytes.Buffer
+		if err := gob.NewEncoder(&buff).Encode(fn()); err != nil {
+			return nil, err
+		}
+		return buff.Bytes(), nil
+	}
+}
+
+// registered returns the function registered under the specified name, or nil.
+func registered(name string) func() ([]byte, error) {
+	registrymu.Lock()
+	defer registrymu.Unlock()
+	return registry[name]
+}
+
+// RunRegistered checks if the current process has been started by
+// [ExecuteInChild] as a child process in order to run a registered function.
+// In this case, RunRegistered runs the requested function, sends its result
+// back to the caller, and then terminates the process. Otherwise,
+// RunRegistered simply returns.
+//
+// RunRegistered must be called from TestMain before running the tests:
+//
+//	func TestMain(m *testing.M) {
+//		spacer.RunRegistered()
+//		os.Exit(m.Run())
+//	}
+func RunRegistered() {
+	if os.Getenv(childEnv) == "" {
+		return
+	}
+	conn, err := uds.NewUnixConn(3, "caller")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "spacer child: invalid fd 3: %s\n", err.Error())
+		os.Exit(1)
+	}
+	defer os.Exit(0)
+	defer func() { _ = conn.Close() }()
+
+	dec := gobmsg.NewDecoder()
+	n, fds, err := conn.ReceiveWithFds(dec.Buffer(), maxCallFds)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "spacer child: cannot receive call: %s\n", err.Error())
+		return
+	}
+	var req api.CallRequest
+	if err := dec.Decode(n, &req); err != nil {
+		fmt.Fprintf(os.Stderr, "spacer child: cannot decode call: %s\n", err.Error())
+		return
+	}
+	req.DecodeFds(fds)
+
+	resp := call(req)
+	msg, err := gobmsg.NewEncoder().Encode(&resp)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "spacer child: cannot encode result: %s\n", err.Error())
+		return
+	}
+	if _, err := conn.SendWithFds(msg); err != nil {
+		fmt.Fprintf(os.Stderr, "spacer child: cannot send result: %s\n", err.Error())
+	}
+}
+
+// childFailure is raised as a panic by the Gomega fail handler in a child
+// process, carrying the failure message.
+type childFailure string
+
+// call runs the registered function requested by the passed call request,
+// attached to the namespaces referenced by the file descriptors in the
+// request, and returns the function's outcome.
+func call(req api.CallRequest) (resp api.CallResponse) {
+	defer func() {
+		for _, fd := range req.Nsfds {
+			_ = unix.Close(fd)
+		}
+	}()
+
+	fn := registered(req.Name)
+	if fn == nil {
+		resp.Error = "no function " + strconv.Quote(req.Name) + " registered"
+		return
+	}
+	g.RegisterFailHandler(func(message string, _ ...int) {
+		panic(childFailure(message))
+	})
+	run := func() {
+		defer func() {
+			switch r := recover().(type) {
+			case nil:
+			case childFailure:
+				resp.Failure = string(r)
+			default:
+				resp.Panic = fmt.Sprint(r)
+			}
+		}()
+		result, err := fn()
+		if err != nil {
+			resp.Error = "cannot encode result: " + err.Error()
+			return
+		}
+		resp.Result = result
+	}
+	if err := attach(req.Nsfds); err != nil {
+		resp.Error = err.Error()
+		return
+	}
+	run()
+	return
+}
+
+// attach the calling OS-level thread to the namespaces referenced by the
+// passed file descriptors. As the child process terminates after running the
+// registered function, attach doesn't care about ever restoring the original
+// namespaces; the child process usually isn't even allowed to do so anyway.
+func attach(nsfds []int) error {
+	runtime.LockOSThread() // never unlock, as this thread is going to be tainted
+	for _, nsfd := range nsfds {
+		typ, err := unix.IoctlRetInt(nsfd, spacetest.NS_GET_NSTYPE)
+		if err != nil {
+			return fmt.Errorf("cannot determine type of namespace fd %d: %w", nsfd, err)
+		}
+		if typ == unix.CLONE_NEWNS {
+			if err := unix.Unshare(unix.CLONE_FS); err != nil {
+				return fmt.Errorf("cannot unshare file attributes: %w", err)
+			}
+		}
+		if err := unix.Setns(nsfd, typ); err != nil {
+			return fmt.Errorf("cannot switch into %s namespace: %w", spacetest.Name(typ), err)
+		}
+	}
+	return nil
+}
+
+// ChildFailure is returned by [ExecuteInChildE] when a registered function
+// failed a Gomega assertion in the child process, carrying the failure
+// message.
+type ChildFailure struct {
+	Message string
+}
+
+// Error returns the failure message of the registered function.
+func (f *ChildFailure) Error() string {
+	return f.Message
+}
+
+// ExecuteInChild runs the function registered under the specified name (see
+// [Register]) in a new child process of the spacer service connected to the
+// specified client, returning the function's result. The child process runs
+// inside the user and PID namespaces of the spacer service; while running the
+// registered function, the child process additionally is attached to the
+// namespaces referenced by the passed file descriptors.
+//
+// ExecuteInChild thus allows running functions inside user namespaces that
+// [spacetest.Execute] cannot switch into. The passed file descriptors may
+// reference the user namespace of the connected spacer service, but not any
+// other user namespace.
+//
+// If the registered function fails a Gomega assertion in the child process,
+// ExecuteInChild fails the current test with the same failure message. The
+// test binary must call [RunRegistered] in its TestMain.
+func ExecuteInChild[T any](c *Client, name string, nsfds ...int) T {
+	gi.GinkgoHelper()
+
+	result, err := ExecuteInChildE[T](c, name, nsfds...)
+	var failure *ChildFailure
+	if errors.As(err, &failure) {
+		gi.Fail(failure.Message)
+	}
+	g.Expect(err).NotTo(g.HaveOccurred())
+	return result
+}
+
+// ExecuteInChildE works like [ExecuteInChild], but returns an error instead of
+// failing the current test. If the registered function fails a Gomega
+// assertion, the error returned is a [*ChildFailure].
+func ExecuteInChildE[T any](c *Client, name string, nsfds ...int) (T, error) {
+	var zero T
+	if registered(name) == nil {
+		return zero, fmt.Errorf("cannot ExecuteInChild(), no function %q registered", name)
+	}
+	callfds, err := c.callFds(nsfds)
+	if err != nil {
+		return zero, err
+	}
+
+	exe, err := os.Executable()
+	if err != nil {
+		return zero, fmt.Errorf("cannot determine executable: %w", err)
+	}
+	resp, err := doE[*api.ExecResponse](c, api.ExecRequest{
+		Path: exe,
+		Args: []string{"-test.run=^$"},
+		Env:  []string{childEnv + "=" + name},
+	}, "exec")
+	if err != nil {
+		return zero, err
+	}
+	defer func() { _ = unix.Close(resp.PIDFd) }()
+	conn, err := uds.NewUnixConn(resp.Conn, "child")
+	if err != nil {
+		return zero, fmt.Errorf("child connection failure: %w", err)
+	}
+	defer func() { _ = conn.Close() }()
+
+	msg, err := gobmsg.NewEncoder().Encode(&api.CallRequest{Name: name})
+	if err != nil {
+		return zero, fmt.Errorf("cannot encode call request: %w", err)
+	}
+	if _, err := conn.SendWithFds(msg, callfds...); err != nil {
+		return zero, fmt.Errorf("cannot send call request: %w", err)
+	}
+	dec := gobmsg.NewDecoder()
+	n, fds, err := conn.ReceiveWithFds(dec.Buffer(), 0)
+	for _, fd := range fds {
+		_ = unix.Close(fd)
+	}
+	if err != nil {
+		return zero, fmt.Errorf("cannot receive call response: %w", err)
+	}
+	var callresp api.CallResponse
+	if err := dec.Decode(n, &callresp); err != nil {
+		return zero, fmt.Errorf("cannot decode call response: %w", err)
+	}
+	switch {
+	case callresp.Failure != "":
+		return zero, &ChildFailure{Message: callresp.Failure}
+	case callresp.Panic != "":
+		return zero, fmt.Errorf("function %q panicked in child: %s", name, callresp.Panic)
+	case callresp.Error != "":
+		return zero, fmt.Errorf("cannot run function %q in child: %s", name, callresp.Error)
+	}
+	var result T
+	if err := gob.NewDecoder(bytes.NewReader(callresp.Result)).Decode(&result); err != nil {
+		return zero, fmt.Errorf("cannot decode result of function %q: %w", name, err)
+	}
+	return result, nil
+}
+
+// callFds returns the passed namespace file descriptors to be attached to in a
+// child process of the connected spacer service, leaving out the user
+// namespace of the connected spacer service, as the child process already is
+// attached to it. callFds returns an error if any of the file descriptors
+// references a different user namespace.
+func (c *Client) callFds(nsfds []int) ([]int, error) {
+	pid := "self"
+	if c.pid != 0 {
+		pid = strconv.Itoa(c.pid)
+	}
+	var userns unix.Stat_t
+	if err := unix.Stat("/proc/"+pid+"/ns/user", &userns); err != nil {
+		return nil, fmt.Errorf("cannot determine user namespace of spacer service: %w", err)
+	}
+	callfds := make([]int, 0, len(nsfds))
+	for _, nsfd := range nsfds {
+		typ, err := unix.IoctlRetInt(nsfd, spacetest.NS_GET_NSTYPE)
+		if err != nil {
+			return nil, fmt.Errorf("cannot determine type of namespace fd %d: %w", nsfd, err)
+		}
+		if typ != unix.CLONE_NEWUSER {
+			callfds = append(callfds, nsfd)
+			continue
+		}
+		var stat unix.Stat_t
+		if err := unix.Fstat(nsfd, &stat); err != nil {
+			return nil, fmt.Errorf("cannot stat namespace fd %d: %w", nsfd, err)
+		}
+		if stat.Dev != userns.Dev || stat.Ino != userns.Ino {
+			return nil, errors.New("cannot ExecuteInChild() in user namespace other than the spacer service's")
+		}
+	}
+	if len(callfds) > maxCallFds {
+		return nil, fmt.Errorf("cannot ExecuteInChild() with more than %d namespaces", maxCallFds)
+	}
+	return callfds, nil
+}
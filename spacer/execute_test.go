@@ -0,0 +1,114 @@
+// Copyright 2025 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spacer
+
+import (
+	"context"
+	"os"
+	"time"
+
+	"github.com/thediveo/spacetest"
+	"golang.org/x/sys/unix"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gleak"
+	. "github.com/thediveo/fdooze"
+)
+
+type identity struct {
+	UID, PID int
+	NetIno   uint64
+}
+
+func init() {
+	Register("identity", func() identity {
+		return identity{
+			UID:    os.Getuid(),
+			PID:    os.Getpid(),
+			NetIno: spacetest.CurrentIno(unix.CLONE_NEWNET),
+		}
+	})
+	Register("failing", func() int {
+		Expect(42).To(Equal(666), "unexpected answer")
+		return 42
+	})
+	Register("panicking", func() int {
+		panic("D'oh!")
+	})
+}
+
+var _ = Describe("executing registered functions in child processes", func() {
+
+	BeforeEach(func() {
+		goodfds := Filedescriptors()
+		goodgos := Goroutines()
+		DeferCleanup(func() {
+			Eventually(Goroutines).Within(2 * time.Second).ProbeEvery(100 * time.Millisecond).
+				ShouldNot(HaveLeaked(goodgos))
+			Expect(Filedescriptors()).NotTo(HaveLeakedFds(goodfds))
+		})
+	})
+
+	It("rejects unregistered functions", func(ctx context.Context) {
+		cl := New(ctx, WithOut(GinkgoWriter), WithErr(GinkgoWriter))
+		defer cl.Close()
+
+		Expect(ExecuteInChildE[int](cl, "nonexisting")).Error().To(
+			MatchError(ContainSubstring(`no function "nonexisting" registered`)))
+	})
+
+	It("runs a registered function inside the user and PID namespaces of a subspace", func(ctx context.Context) {
+		cl := New(ctx, WithOut(GinkgoWriter), WithErr(GinkgoWriter))
+		defer cl.Close()
+		subcl, spc := cl.Subspace(true, true)
+		defer subcl.Close()
+		netnsfd := subcl.NewTransient(unix.CLONE_NEWNET)
+
+		id := ExecuteInChild[identity](subcl, "identity", spc.User, netnsfd)
+		Expect(id.UID).To(BeZero())
+		Expect(id.PID).NotTo(Equal(os.Getpid()))
+		Expect(id.PID).To(BeNumerically("<", 10))
+		Expect(id.NetIno).To(Equal(spacetest.Ino(netnsfd, unix.CLONE_NEWNET)))
+	})
+
+	It("rejects a user namespace not belonging to the spacer service", func(ctx context.Context) {
+		cl := New(ctx, WithOut(GinkgoWriter), WithErr(GinkgoWriter))
+		defer cl.Close()
+		subcl, spc := cl.Subspace(true, false)
+		defer subcl.Close()
+
+		Expect(ExecuteInChildE[identity](cl, "identity", spc.User)).Error().To(
+			MatchError(ContainSubstring("in user namespace other than the spacer service's")))
+	})
+
+	It("passes back failures and panics", func(ctx context.Context) {
+		cl := New(ctx, WithOut(GinkgoWriter), WithErr(GinkgoWriter))
+		defer cl.Close()
+		subcl, _ := cl.Subspace(true, false)
+		defer subcl.Close()
+
+		_, err := ExecuteInChildE[int](subcl, "failing")
+		var failure *ChildFailure
+		Expect(err).To(BeAssignableToTypeOf(failure))
+		Expect(err).To(MatchError(And(
+			ContainSubstring("unexpected answer"),
+			ContainSubstring("666"))))
+
+		Expect(ExecuteInChildE[int](subcl, "panicking")).Error().To(
+			MatchError(ContainSubstring(`function "panicking" panicked in child: D'oh!`)))
+	})
+
+})
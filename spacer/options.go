@@ -36,3 +36,13 @@ func WithErr(w io.Writer) Option {
 		return nil
 	}
 }
+
+// WithServiceBinary configures a spacer Client to use the specified pre-built
+// spacer service binary instead of building it on demand. This is especially
+// useful when using [NewE] outside Ginkgo tests.
+func WithServiceBinary(path string) Option {
+	return func(c *Client) error {
+		c.servicebin = path
+		return nil
+	}
+}
@@ -15,6 +15,7 @@
 package spacer
 
 import (
+	"os"
 	"testing"
 
 	. "github.com/onsi/ginkgo/v2"
@@ -26,6 +27,11 @@ var _ = AfterSuite(func() {
 	gexec.CleanupBuildArtifacts()
 })
 
+func TestMain(m *testing.M) {
+	RunRegistered()
+	os.Exit(m.Run())
+}
+
 func TestSpacer(t *testing.T) {
 	RegisterFailHandler(Fail)
 	RunSpecs(t, "spacetest/spacer package")
@@ -35,6 +35,7 @@ import (
 type Spacer interface {
 	Subspace(*api.SubspaceRequest) api.Response
 	Room(*api.RoomsRequest) api.Response
+	Exec(*api.ExecRequest) api.Response
 	Slog() *slog.Logger
 }
 
@@ -108,6 +109,8 @@ func Serve(ctx context.Context, conn *uds.Conn, spacer Spacer) {
 			resp = spacer.Subspace(req)
 		case *api.RoomsRequest:
 			resp = spacer.Room(req)
+		case *api.ExecRequest:
+			resp = spacer.Exec(req)
 		default:
 			spacer.Slog().Error("unhandled request",
 				slog.String("spacer-id", id),
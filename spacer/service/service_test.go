@@ -114,4 +114,9 @@ func (m *closingmock) Subspace(req *api.SubspaceRequest) api.Response {
 	return &api.ErrorResponse{Reason: "not mocked"}
 }
 
+func (m *closingmock) Exec(req *api.ExecRequest) api.Response {
+	_ = m.conn.Close()
+	return &api.ErrorResponse{Reason: "not mocked"}
+}
+
 func (m *closingmock) Slog() *slog.Logger { return slog.Default() }
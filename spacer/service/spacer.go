@@ -344,3 +344,86 @@ func (s *Spacemaker) newNamespace(typ int) (int, error) {
 	}
 	return newns, nil
 }
+
+// Exec starts the requested executable as a child process of this spacer
+// service, so that the child process runs inside the same user and PID
+// namespaces as the spacer service. Exec returns a file descriptor for a unix
+// domain socket that is connected to the child process on its fd 3, as well
+// as a PID fd referencing the child process.
+func (s *Spacemaker) Exec(req *api.ExecRequest) api.Response {
+	if req.Path == "" {
+		return &api.ErrorResponse{Reason: "no executable specified"}
+	}
+
+	dupond, dupont, err := uds.NewPair()
+	defer func() {
+		_ = dupond.Close()
+		_ = dupont.Close()
+	}()
+	if err != nil {
+		s.Slog().Error("cannot create unix domain socket pair",
+			slog.Int("PID", os.Getpid()),
+			slog.String("err", err.Error()))
+		return &api.ErrorResponse{Reason: "failed to create unix domain socket pair, reason: " + err.Error()}
+	}
+
+	dupontf, err := dupont.File()
+	if err != nil {
+		s.Slog().Error("cannot fetch child *os.File",
+			slog.Int("PID", os.Getpid()),
+			slog.String("err", err.Error()))
+		return &api.ErrorResponse{Reason: "failed to fetch child *os.File, reason: " + err.Error()}
+	}
+	defer func() { _ = dupontf.Close() }()
+
+	child := exec.Command(req.Path, req.Args...)
+	child.Env = append(os.Environ(), req.Env...)
+	child.Stdout = cmp.Or(s.Stdout, io.Writer(os.Stdout))
+	child.Stderr = cmp.Or(s.Stderr, io.Writer(os.Stderr))
+	child.ExtraFiles = []*os.File{dupontf}
+	s.Slog().Info("starting child", slog.String("path", req.Path))
+	if err := child.Start(); err != nil {
+		s.Slog().Error("cannot start child",
+			slog.Int("PID", os.Getpid()),
+			slog.String("err", err.Error()))
+		return &api.ErrorResponse{Reason: "failed to start child, reason: " + err.Error()}
+	}
+	procidfd, err := unix.PidfdOpen(child.Process.Pid, 0)
+	go func() {
+		childpid := child.Process.Pid
+		s.Slog().Info("waiting in background for child to terminate",
+			slog.Int("pid", childpid))
+		_ = child.Wait()
+		s.Slog().Info("child terminated", slog.Int("pid", childpid))
+	}()
+	if err != nil {
+		s.Slog().Error("cannot get PID fd",
+			slog.Int("PID", os.Getpid()),
+			slog.String("err", err.Error()))
+		return &api.ErrorResponse{Reason: "cannot get PID fd, reason: " + err.Error()}
+	}
+
+	dupondf, err := dupond.File()
+	if err != nil {
+		_ = unix.Close(procidfd)
+		s.Slog().Error("cannot fetch client *os.File",
+			slog.Int("PID", os.Getpid()),
+			slog.String("err", err.Error()))
+		return &api.ErrorResponse{Reason: "failed to fetch client *os.File, reason: " + err.Error()}
+	}
+	defer func() { _ = dupondf.Close() }()
+
+	connfd, err := unix.Dup(int(dupondf.Fd()))
+	if err != nil {
+		_ = unix.Close(procidfd)
+		s.Slog().Error("cannot fetch client fd",
+			slog.Int("PID", os.Getpid()),
+			slog.String("err", err.Error()))
+		return &api.ErrorResponse{Reason: "failed to fetch client fd, reason: " + err.Error()}
+	}
+
+	return &api.ExecResponse{
+		Conn:  connfd,
+		PIDFd: procidfd,
+	}
+}
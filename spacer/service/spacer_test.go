@@ -64,6 +64,22 @@ var _ = Describe("serving space", func() {
 
 	})
 
+	Context("Exec service", func() {
+
+		It("rejects invalid params", func() {
+			sm := &Spacemaker{}
+			Expect(sm.Exec(&api.ExecRequest{})).To(api.HaveFailed())
+		})
+
+		It("fails when unable to start child", func() {
+			var out safe.Buffer
+			sm := &Spacemaker{Stderr: &out}
+			Expect(sm.Exec(&api.ExecRequest{Path: "/not-existing"})).To(api.HaveFailed())
+			Expect(out.String()).To(MatchRegexp(`cannot start child.*fork/exec`))
+		})
+
+	})
+
 	Context("Room service", func() {
 
 		It("rejects invalid params", func() {
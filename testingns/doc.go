@@ -0,0 +1,30 @@
+/*
+Package testingns adapts the spacetest helpers to the standard [testing]
+package, for tests not using Ginkgo.
+
+Instead of scheduling Ginkgo cleanups and failing with Gomega, the helpers in
+this package register their cleanups using [testing.TB.Cleanup] and report
+failures using [testing.TB.Fatalf]. Otherwise, they keep the same OS-level
+thread locking guarantees as their Ginkgo-oriented counterparts in
+[github.com/thediveo/spacetest].
+
+# Usage
+
+	import "github.com/thediveo/spacetest/testingns"
+
+	func TestSomething(t *testing.T) {
+	    testingns.EnterTransient(t, unix.CLONE_NEWNET)
+	    // ...now inside a new network namespace until the test ends.
+	}
+
+Please note that [EnterTransient] switches the test's go routine and its locked
+OS-level thread. As the standard testing package runs cleanups on the test's
+go routine after the test function returned, the original namespace gets
+restored before the go routine gets unlocked from its OS-level thread.
+
+For mount namespaces, please use the
+[github.com/thediveo/spacetest/testingns/mntns] package. For creating user
+and PID namespaces, please use the
+[github.com/thediveo/spacetest/testingns/spacer] package.
+*/
+package testingns
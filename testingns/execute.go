@@ -0,0 +1,39 @@
+// Copyright 2025 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testingns
+
+import (
+	"testing"
+
+	"github.com/thediveo/spacetest"
+)
+
+// Execute the passed fn synchronously while attached to the specified
+// namespace(s) and otherwise defaulting to the caller's currently attached
+// namespaces. Please see [spacetest.Execute] for details.
+//
+// When a mount namespace is passed in, fn runs on a separate throw-away go
+// routine. In this case, fn must not call [testing.TB.FailNow] or
+// [testing.TB.Fatalf], as these must be only called from the test's go
+// routine; use [testing.TB.Errorf] instead.
+//
+// If switching namespaces fails, Execute fails the test.
+func Execute(t testing.TB, fn func(), nsref spacetest.NamespaceRef, nsrefs ...spacetest.NamespaceRef) {
+	t.Helper()
+
+	if err := spacetest.ExecuteE(fn, nsref, nsrefs...); err != nil {
+		t.Fatalf("cannot execute in namespace(s): %s", err)
+	}
+}
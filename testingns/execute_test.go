@@ -0,0 +1,38 @@
+// Copyright 2025 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testingns
+
+import (
+	"testing"
+
+	"github.com/thediveo/spacetest"
+	"golang.org/x/sys/unix"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestExecute(t *testing.T) {
+	needsRoot(t)
+	g := NewWithT(t)
+
+	netns := NewTransientNamespace(t, unix.CLONE_NEWNET)
+	count := 0
+	Execute(t, func() {
+		count++
+		g.Expect(netns).To(spacetest.BeCurrentNamespace())
+	}, netns)
+	g.Expect(count).To(Equal(1))
+	g.Expect(netns).NotTo(spacetest.BeCurrentNamespace())
+}
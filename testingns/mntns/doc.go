@@ -0,0 +1,9 @@
+/*
+Package mntns adapts the spacetest/mntns helpers for transient mount namespaces
+to the standard [testing] package, for tests not using Ginkgo.
+
+Same as with [github.com/thediveo/spacetest/mntns], the OS-level threads that
+have been attached to transient mount namespaces never get unlocked from their
+go routines, so that they get thrown away when their go routines finish.
+*/
+package mntns
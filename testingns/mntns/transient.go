@@ -0,0 +1,75 @@
+// Copyright 2025 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mntns
+
+import (
+	"testing"
+
+	"github.com/thediveo/spacetest"
+	"github.com/thediveo/spacetest/mntns"
+)
+
+// EnterTransient creates and enters a new mount namespace with “/” remounted
+// to private mount point propagation, switching the OS-level thread back to
+// its original mount namespace when the test ends.
+//
+// The calling go routine stays locked to its OS-level thread even after the
+// test ends, as unsharing the filesystem attributes cannot be undone. The
+// tainted OS-level thread thus gets thrown away when the test's go routine
+// finishes.
+//
+// If anything fails, EnterTransient fails the test.
+func EnterTransient(t testing.TB) {
+	t.Helper()
+
+	leave, err := mntns.EnterTransientE()
+	if err != nil {
+		t.Fatalf("cannot enter transient mount namespace: %s", err)
+	}
+	t.Cleanup(func() {
+		if err := leave(); err != nil {
+			t.Fatalf("leaving from EnterTransient: %s", err)
+		}
+	})
+}
+
+// NewTransient creates a new transient mount namespace that is kept alive by
+// an idle OS-level thread, returning a file descriptor referencing the new
+// mount namespace as well as the path of the root directory of the idle
+// thread, as seen in the caller's mount namespace.
+//
+// When the test ends, the returned file descriptor gets closed and the idle
+// thread terminated and thrown away.
+//
+// If anything fails, NewTransient fails the test.
+func NewTransient(t testing.TB) (mntfd int, procfsroot string) {
+	t.Helper()
+
+	ns, procfsroot := NewTransientNamespace(t)
+	return ns.Fd(), procfsroot
+}
+
+// NewTransientNamespace works like [NewTransient], but returns a
+// [spacetest.Namespace] instead of a bare file descriptor.
+func NewTransientNamespace(t testing.TB) (ns *spacetest.Namespace, procfsroot string) {
+	t.Helper()
+
+	ns, procfsroot, release, err := mntns.NewTransientNamespaceE()
+	if err != nil {
+		t.Fatalf("cannot create transient mount namespace: %s", err)
+	}
+	t.Cleanup(release)
+	return ns, procfsroot
+}
@@ -0,0 +1,63 @@
+// Copyright 2025 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mntns
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/thediveo/spacetest"
+	"github.com/thediveo/spacetest/testingns"
+	"golang.org/x/sys/unix"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestNewTransient(t *testing.T) {
+	if os.Getuid() != 0 {
+		t.Skip("needs root")
+	}
+	g := NewWithT(t)
+
+	var mntnsfd int
+	t.Run("create", func(t *testing.T) {
+		g := NewWithT(t)
+		var procfsroot string
+		mntnsfd, procfsroot = NewTransient(t)
+		g.Expect(mntnsfd).To(spacetest.BeSameNamespaceAs(filepath.Join(procfsroot, "../ns/mnt")))
+		g.Expect(mntnsfd).NotTo(spacetest.BeSameNamespaceAs("/proc/self/ns/mnt"))
+		var current bool
+		testingns.Execute(t, func() {
+			// fn runs on a separate go routine, so we must not fail here.
+			current, _ = spacetest.BeCurrentNamespace().Match(mntnsfd)
+		}, spacetest.Fd(mntnsfd))
+		g.Expect(current).To(BeTrue(), "not executed in transient mount namespace")
+	})
+	g.Expect(unix.Fstat(mntnsfd, &unix.Stat_t{})).To(MatchError(unix.EBADF),
+		"transient mount namespace fd not closed")
+}
+
+func TestEnterTransient(t *testing.T) {
+	if os.Getuid() != 0 {
+		t.Skip("needs root")
+	}
+
+	t.Run("inside", func(t *testing.T) {
+		g := NewWithT(t)
+		EnterTransient(t)
+		g.Expect("/proc/thread-self/ns/mnt").NotTo(spacetest.BeSameNamespaceAs("/proc/self/ns/mnt"))
+	})
+}
@@ -0,0 +1,87 @@
+// Copyright 2025 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spacer
+
+import (
+	"testing"
+
+	"github.com/thediveo/spacetest/spacer"
+	"github.com/thediveo/spacetest/spacer/api"
+	"golang.org/x/sys/unix"
+)
+
+// New returns a new client connected to a new spacer service instance. The
+// client gets automatically closed when the test ends, causing the spacer
+// service instance to terminate.
+//
+// If anything fails, New fails the test.
+func New(t testing.TB, opts ...spacer.Option) *spacer.Client {
+	t.Helper()
+
+	c, err := spacer.NewE(t.Context(), opts...)
+	if err != nil {
+		t.Fatalf("cannot start spacer service: %s", err)
+	}
+	t.Cleanup(c.Close)
+	return c
+}
+
+// Subspace returns a new client as well as new user and/or PID child
+// namespaces, using the spacer service the passed client is connected to.
+// Please see [spacer.Client.Subspace] for details.
+//
+// When the test ends, the returned client gets closed, as well as the file
+// descriptors of the namespaces returned. Callers thus must not close the
+// returned file descriptors themselves.
+//
+// If anything fails, Subspace fails the test.
+func Subspace(t testing.TB, c *spacer.Client, user, pid bool) (*spacer.Client, api.Subspaces) {
+	t.Helper()
+
+	subclnt, subspaces, err := c.SubspaceE(user, pid)
+	if err != nil {
+		t.Fatalf("cannot create subspace: %s", err)
+	}
+	t.Cleanup(func() {
+		subclnt.Close()
+		if subspaces.PID > 0 {
+			_ = unix.Close(subspaces.PID)
+		}
+		if subspaces.User > 0 {
+			_ = unix.Close(subspaces.User)
+		}
+	})
+	return subclnt, subspaces
+}
+
+// NewTransient creates a new Linux kernel namespace of the specified type using
+// the spacer service the passed client is connected to, returning a file
+// descriptor referencing the newly created namespace. Please see
+// [spacer.Client.NewTransient] for the supported types of namespaces.
+//
+// When the test ends, the returned file descriptor gets closed. The caller thus
+// must not close the file descriptor returned.
+//
+// If anything fails, NewTransient fails the test.
+func NewTransient(t testing.TB, c *spacer.Client, typ int) int {
+	t.Helper()
+
+	nsfd, err := c.NewTransientE(typ)
+	if err != nil {
+		t.Fatalf("cannot create transient namespace: %s", err)
+	}
+	t.Cleanup(func() { _ = unix.Close(nsfd) })
+	return nsfd
+}
@@ -0,0 +1,46 @@
+// Copyright 2025 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spacer
+
+import (
+	"os"
+	"testing"
+
+	"github.com/onsi/gomega/gexec"
+	"github.com/thediveo/spacetest"
+	"github.com/thediveo/spacetest/spacer"
+	"golang.org/x/sys/unix"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestMain(m *testing.M) {
+	code := m.Run()
+	gexec.CleanupBuildArtifacts()
+	os.Exit(code)
+}
+
+func TestSubspace(t *testing.T) {
+	g := NewWithT(t)
+
+	clnt := New(t, spacer.WithOut(os.Stderr), spacer.WithErr(os.Stderr))
+	subclnt, spc := Subspace(t, clnt, true, false)
+	g.Expect(subclnt.PID()).NotTo(BeZero())
+	g.Expect(spc.User).To(spacetest.BeChildNamespaceOf("/proc/self/ns/user"))
+
+	netnsfd := NewTransient(t, subclnt, unix.CLONE_NEWNET)
+	g.Expect(netnsfd).To(spacetest.BeNamespaceOfType(unix.CLONE_NEWNET))
+	g.Expect(netnsfd).To(spacetest.BeOwnedByUserNamespace(spc.User))
+}
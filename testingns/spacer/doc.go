@@ -0,0 +1,17 @@
+/*
+Package spacer adapts the spacetest/spacer client for creating user and PID
+namespaces to the standard [testing] package, for tests not using Ginkgo.
+
+# Important
+
+Unless a pre-built spacer service binary is specified using
+[spacer.WithServiceBinary], [New] builds the spacer service binary on demand.
+Make sure to call [gexec.CleanupBuildArtifacts] in your TestMain in this case.
+
+[gexec.CleanupBuildArtifacts]: https://pkg.go.dev/github.com/onsi/gomega/gexec#CleanupBuildArtifacts
+*/
+package spacer
+
+import "github.com/thediveo/spacetest/spacer"
+
+var _ = spacer.WithServiceBinary // make spacer.xxx true hyperlinks
@@ -0,0 +1,74 @@
+// Copyright 2025 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testingns
+
+import (
+	"testing"
+
+	"github.com/thediveo/spacetest"
+)
+
+// EnterTransient creates and enters a new (and isolated) Linux kernel namespace
+// of the specified type, switching the calling go routine and its locked
+// OS-level thread back when the test ends. Please see
+// [spacetest.EnterTransient] for the supported types of namespaces.
+//
+// EnterTransient locks the caller's go routine to its OS-level thread and
+// unlocks it only after having successfully restored the original namespace.
+//
+// If anything fails, EnterTransient fails the test.
+func EnterTransient(t testing.TB, typ int) {
+	t.Helper()
+
+	leave, err := spacetest.EnterTransientE(typ)
+	if err != nil {
+		t.Fatalf("cannot enter transient namespace: %s", err)
+	}
+	t.Cleanup(func() {
+		if err := leave(); err != nil {
+			t.Fatalf("leaving from EnterTransient: %s", err)
+		}
+	})
+}
+
+// NewTransient creates a new Linux kernel namespace of the specified type, but
+// doesn't enter it. Instead, it returns a file descriptor referencing the newly
+// created namespace. Please see [spacetest.NewTransient] for the supported
+// types of namespaces.
+//
+// NewTransient registers a cleanup with the test to close the returned file
+// descriptor when the test ends. The caller thus must not close the file
+// descriptor returned.
+//
+// If anything fails, NewTransient fails the test.
+func NewTransient(t testing.TB, typ int) int {
+	t.Helper()
+
+	return NewTransientNamespace(t, typ).Fd()
+}
+
+// NewTransientNamespace works like [NewTransient], but returns a
+// [spacetest.Namespace] instead of a bare file descriptor. The returned
+// Namespace gets automatically closed when the test ends.
+func NewTransientNamespace(t testing.TB, typ int) *spacetest.Namespace {
+	t.Helper()
+
+	ns, err := spacetest.NewTransientNamespaceE(typ)
+	if err != nil {
+		t.Fatalf("cannot create transient namespace: %s", err)
+	}
+	t.Cleanup(func() { _ = ns.Close() })
+	return ns
+}
@@ -0,0 +1,112 @@
+// Copyright 2025 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testingns
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"testing"
+
+	"github.com/thediveo/spacetest"
+	"golang.org/x/sys/unix"
+
+	. "github.com/onsi/gomega"
+)
+
+func needsRoot(t *testing.T) {
+	t.Helper()
+	if os.Getuid() != 0 {
+		t.Skip("needs root")
+	}
+}
+
+// currentNamespace returns an open file descriptor referencing the current
+// namespace of the specified type name, closing it when the test ends.
+func currentNamespace(t *testing.T, name string) int {
+	t.Helper()
+	fd, err := unix.Open("/proc/thread-self/ns/"+name, unix.O_RDONLY|unix.O_CLOEXEC, 0)
+	if err != nil {
+		t.Fatalf("cannot open current %s namespace: %s", name, err)
+	}
+	t.Cleanup(func() { _ = unix.Close(fd) })
+	return fd
+}
+
+func TestEnterTransient(t *testing.T) {
+	needsRoot(t)
+
+	t.Run("inside", func(t *testing.T) {
+		g := NewWithT(t)
+		runtime.LockOSThread()
+		defer runtime.UnlockOSThread()
+
+		orignsfd := currentNamespace(t, "uts")
+		t.Cleanup(func() {
+			// cleanups run in LIFO order on the test's go routine, so the
+			// original namespace has already been restored at this point.
+			g.Expect(orignsfd).To(spacetest.BeCurrentNamespace())
+		})
+		EnterTransient(t, unix.CLONE_NEWUTS)
+		g.Expect(orignsfd).NotTo(spacetest.BeCurrentNamespace())
+		g.Expect(currentNamespace(t, "uts")).To(spacetest.BeNamespaceOfType(unix.CLONE_NEWUTS))
+	})
+}
+
+func TestEnterTransientRejects(t *testing.T) {
+	ft := &fatalT{TB: t}
+	ft.run(func() { EnterTransient(ft, unix.CLONE_NEWNS) })
+	NewWithT(t).Expect(ft.msg).To(ContainSubstring("unsupported type mnt"))
+}
+
+func TestNewTransient(t *testing.T) {
+	needsRoot(t)
+	g := NewWithT(t)
+
+	var nsfd int
+	t.Run("create", func(t *testing.T) {
+		g := NewWithT(t)
+		nsfd = NewTransient(t, unix.CLONE_NEWNET)
+		g.Expect(nsfd).To(spacetest.BeNamespaceOfType(unix.CLONE_NEWNET))
+		g.Expect(nsfd).NotTo(spacetest.BeCurrentNamespace())
+
+		ns := NewTransientNamespace(t, unix.CLONE_NEWIPC)
+		g.Expect(ns.Type()).To(Equal(unix.CLONE_NEWIPC))
+	})
+	g.Expect(unix.Fstat(nsfd, &unix.Stat_t{})).To(MatchError(unix.EBADF),
+		"transient namespace fd not closed")
+}
+
+// fatalT intercepts Fatalf calls, allowing tests to check for expected test
+// failures without failing themselves.
+type fatalT struct {
+	testing.TB
+	msg string
+}
+
+func (f *fatalT) Fatalf(format string, args ...any) {
+	f.msg = fmt.Sprintf(format, args...)
+	runtime.Goexit()
+}
+
+// run fn on a separate go routine, so Fatalf can terminate it.
+func (f *fatalT) run(fn func()) {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		fn()
+	}()
+	<-done
+}
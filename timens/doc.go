@@ -0,0 +1,38 @@
+/*
+Package timens supports running child processes in transient time namespaces
+with configurable offsets for the CLOCK_MONOTONIC and CLOCK_BOOTTIME clocks.
+
+For instance, this allows deterministic tests of uptime and lease expiry logic
+without the need to wait 400 days for the machine to have been up 400 days.
+
+# Usage
+
+	import "github.com/thediveo/spacetest/timens"
+
+	It("has been up for ages", func() {
+	    timensfd := timens.NewTransient(400*24*time.Hour, 400*24*time.Hour)
+	    uptime := exec.Command("cat", "/proc/uptime")
+	    uptime.Stdout = GinkgoWriter
+	    timens.Run(timensfd, uptime)
+	})
+
+# Restrictions
+
+The Linux kernel does not allow multi-threaded processes – such as all Go
+programs – to switch into a different time namespace: [setns(2)] fails with
+EUSERS (“too many users”). Moreover, [unshare(2)] with CLONE_NEWTIME doesn't
+switch the calling thread into the new time namespace, but only its future
+children. And the clock offsets of a time namespace can only be set as long as
+no process has entered it.
+
+This package thus uses a dedicated idle OS-level thread for each transient time
+namespace, similar to the idler threads of the spacetest/mntns package. Child
+processes started from this idle thread via [Start], [Run], or inside a function
+passed to [Execute], are then created inside the transient time namespace,
+seeing the shifted clocks. Please note that the Go code of the test process
+itself never sees the shifted clocks.
+
+[setns(2)]: https://man7.org/linux/man-pages/man2/setns.2.html
+[unshare(2)]: https://man7.org/linux/man-pages/man2/unshare.2.html
+*/
+package timens
@@ -0,0 +1,87 @@
+// Copyright 2025 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package timens
+
+import (
+	"os/exec"
+
+	"github.com/thediveo/spacetest"
+
+	. "github.com/onsi/ginkgo/v2" //nolint:staticcheck // ST1001 rule does not apply
+	. "github.com/onsi/gomega"    //nolint:staticcheck // ST1001 rule does not apply
+)
+
+// Execute fn synchronously on the idle OS-level thread of the transient time
+// namespace referenced by timensfd, so that any child processes started by fn
+// are created inside the transient time namespace. Please note that fn itself
+// does not see the shifted clocks of the time namespace, only child processes
+// started by fn.
+//
+// If timensfd does not reference a transient time namespace created by
+// [NewTransient], Execute fails the current test. Panics in fn are passed on
+// to the caller.
+func Execute[H spacetest.Handle](timensfd H, fn func()) {
+	GinkgoHelper()
+
+	idler := idlers.lookup(Ino(timensfd))
+	Expect(idler.fnCh).NotTo(BeNil(),
+		"not a transient time namespace created by timens.NewTransient")
+	// The idler might get torn down by a deferred cleanup at any time, so we
+	// must not block forever on submitting fn or waiting for its result.
+	panicCh := make(chan any)
+	submitted := false
+	select {
+	case idler.fnCh <- execution{fn: fn, panicCh: panicCh}:
+		submitted = true
+	case <-idler.gone:
+	}
+	Expect(submitted).To(BeTrue(),
+		"transient time namespace has already been torn down")
+	var r any
+	select {
+	case r = <-panicCh:
+	case <-idler.gone:
+		// As the idler closes the panic channel before it is gone, a result
+		// must be available now that the idler accepted fn.
+		r = <-panicCh
+	}
+	if r != nil {
+		panic(r)
+	}
+}
+
+// Start the specified command as a child process inside the transient time
+// namespace referenced by timensfd, but do not wait for it to complete. If the
+// command cannot be started, Start fails the current test.
+func Start[H spacetest.Handle](timensfd H, cmd *exec.Cmd) {
+	GinkgoHelper()
+
+	Execute(timensfd, func() {
+		Expect(cmd.Start()).To(Succeed(),
+			"cannot start command in transient time namespace")
+	})
+}
+
+// Run the specified command as a child process inside the transient time
+// namespace referenced by timensfd and wait for it to complete. If the command
+// cannot be started or doesn't complete successfully, Run fails the current
+// test.
+func Run[H spacetest.Handle](timensfd H, cmd *exec.Cmd) {
+	GinkgoHelper()
+
+	Start(timensfd, cmd)
+	Expect(cmd.Wait()).To(Succeed(),
+		"command in transient time namespace failed")
+}
@@ -0,0 +1,164 @@
+// Copyright 2025 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package timens
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"sync"
+	"time"
+
+	"golang.org/x/sys/unix"
+
+	. "github.com/onsi/ginkgo/v2" //nolint:staticcheck // ST1001 rule does not apply
+	. "github.com/onsi/gomega"    //nolint:staticcheck // ST1001 rule does not apply
+)
+
+// NewTransient creates a new transient time namespace with the specified
+// offsets for CLOCK_MONOTONIC and CLOCK_BOOTTIME, returning a file descriptor
+// referencing it. The transient time namespace is kept alive by an idle
+// OS-level thread; child processes started from this idle thread using
+// [Start], [Run], or [Execute] are created inside the transient time namespace.
+//
+// NewTransient schedules a Ginkgo deferred cleanup in order to terminate the
+// idle thread and to close the fd referencing the time namespace. The caller
+// thus must not close the file descriptor returned.
+func NewTransient(monotonic, boottime time.Duration) int {
+	GinkgoHelper()
+
+	// closing the done channel tells the Go routine we will kick off next to
+	// call it a day and terminate.
+	done := make(chan struct{})
+	DeferCleanup(func() { close(done) })
+
+	fnCh := make(chan execution)
+	gone := make(chan struct{})
+	readyCh := make(chan int)
+	go func() {
+		defer close(gone)
+		defer GinkgoRecover()
+		runtime.LockOSThread() // never unlock, as this thread is going to be tainted
+
+		// Whatever is going to happen to us, make sure to unblock the receiving
+		// Go routine, and even if this is the zero value...
+		defer close(readyCh)
+
+		Expect(unix.Unshare(unix.CLONE_NEWTIME)).To(Succeed(),
+			"cannot create new time namespace")
+		// As we're not the thread group leader, /proc/self/timens_offsets
+		// would refer to the wrong task; and there's no timens_offsets in
+		// /proc/thread-self, so we need to go through our TID instead.
+		Expect(os.WriteFile(fmt.Sprintf("/proc/%d/timens_offsets", unix.Gettid()),
+			[]byte(offset("monotonic", monotonic)+offset("boottime", boottime)), 0)).To(Succeed(),
+			"cannot set time namespace offsets")
+		timensfd, err := unix.Open("/proc/thread-self/ns/time_for_children", unix.O_RDONLY, 0)
+		Expect(err).NotTo(HaveOccurred(),
+			"cannot determine new time namespace from procfs")
+		DeferCleanup(func() { _ = unix.Close(timensfd) })
+
+		idlers.add(Ino(timensfd), idler{fnCh: fnCh, gone: gone})
+		defer idlers.remove(fnCh)
+
+		readyCh <- timensfd
+
+		for {
+			select {
+			case <-done:
+				return // ...fall off the discworld...
+			case exec := <-fnCh:
+				exec.run()
+			}
+		}
+	}()
+	timensfd := <-readyCh
+	Expect(timensfd).NotTo(BeZero())
+	return timensfd
+}
+
+// offset returns the textual offset specification for the named clock in the
+// format expected by /proc/$PID/timens_offsets, see also [time_namespaces(7)].
+//
+// [time_namespaces(7)]: https://man7.org/linux/man-pages/man7/time_namespaces.7.html
+func offset(clock string, d time.Duration) string {
+	// The nanoseconds part must always be in the range [0, 999999999], so
+	// we need to adjust the seconds part for negative durations accordingly.
+	secs := int64(d / time.Second)
+	nsecs := int64(d % time.Second)
+	if nsecs < 0 {
+		secs--
+		nsecs += int64(time.Second)
+	}
+	return fmt.Sprintf("%s %d %d\n", clock, secs, nsecs)
+}
+
+// execution is a function to be executed on an idler thread, passing back any
+// panic that happened while executing the function.
+type execution struct {
+	fn      func()
+	panicCh chan any
+}
+
+// run the fn of this execution, passing back any panic and finally closing the
+// panic channel.
+func (e execution) run() {
+	defer close(e.panicCh)
+	defer func() {
+		if r := recover(); r != nil {
+			e.panicCh <- r
+		}
+	}()
+	e.fn()
+}
+
+// idler references the idle thread of a transient time namespace in terms of
+// its channel for submitting functions to be executed, and its channel that
+// gets closed when the idle thread has stopped accepting functions.
+type idler struct {
+	fnCh chan execution
+	gone chan struct{}
+}
+
+// idlerRegistry maps the identifiers of transient time namespaces to their
+// idler threads.
+type idlerRegistry struct {
+	mu     sync.Mutex
+	idlers map[uint64]idler
+}
+
+var idlers = idlerRegistry{idlers: map[uint64]idler{}}
+
+func (r *idlerRegistry) add(ino uint64, i idler) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.idlers[ino] = i
+}
+
+func (r *idlerRegistry) remove(fnCh chan execution) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for ino, i := range r.idlers {
+		if i.fnCh == fnCh {
+			delete(r.idlers, ino)
+			return
+		}
+	}
+}
+
+func (r *idlerRegistry) lookup(ino uint64) idler {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.idlers[ino]
+}
@@ -0,0 +1,111 @@
+// Copyright 2025 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package timens
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/thediveo/spacetest"
+	"golang.org/x/sys/unix"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gleak"
+	. "github.com/thediveo/fdooze"
+	. "github.com/thediveo/success"
+)
+
+var _ = Describe("transient time namespaces", func() {
+
+	BeforeEach(func() {
+		if os.Getuid() != 0 {
+			Skip("needs root")
+		}
+		goodfds := Filedescriptors()
+		goodgos := Goroutines()
+		DeferCleanup(func() {
+			Eventually(Goroutines).Within(2 * time.Second).ProbeEvery(250 * time.Millisecond).
+				ShouldNot(HaveLeaked(goodgos))
+			Expect(Filedescriptors()).NotTo(HaveLeakedFds(goodfds))
+		})
+	})
+
+	DescribeTable("clock offsets",
+		func(d time.Duration, expected string) {
+			Expect(offset("monotonic", d)).To(Equal(expected))
+		},
+		Entry(nil, time.Duration(0), "monotonic 0 0\n"),
+		Entry(nil, 42*time.Second+666*time.Nanosecond, "monotonic 42 666\n"),
+		Entry(nil, -1*time.Nanosecond, "monotonic -1 999999999\n"),
+		Entry(nil, -2*time.Second, "monotonic -2 0\n"),
+	)
+
+	It("rejects executing in a non-transient time namespace", func() {
+		Expect(InterceptGomegaFailure(func() {
+			Execute(Current(), func() {})
+		})).To(MatchError(ContainSubstring("not a transient time namespace")))
+		Expect(InterceptGomegaFailure(func() {
+			Execute(spacetest.Current(unix.CLONE_NEWNET), func() {})
+		})).To(MatchError(ContainSubstring("not a time namespace")))
+	})
+
+	It("doesn't block on torn down idlers", func() {
+		timensfd := Current()
+		gone := make(chan struct{})
+		close(gone)
+		fnCh := make(chan execution)
+		idlers.add(Ino(timensfd), idler{fnCh: fnCh, gone: gone})
+		defer idlers.remove(fnCh)
+		Expect(InterceptGomegaFailure(func() {
+			Execute(timensfd, func() {})
+		})).To(MatchError(ContainSubstring("has already been torn down")))
+	})
+
+	It("passes panics back to the caller", func() {
+		timens := NewTransient(0, 0)
+		Expect(func() {
+			Execute(timens, func() { panic("D'OH!") })
+		}).To(PanicWith("D'OH!"))
+	})
+
+	It("runs a child process in a transient time namespace with offsets", func() {
+		const ages = 400 * 24 * time.Hour
+		timens := NewTransient(ages, ages)
+		Expect(Ino(timens)).NotTo(Equal(CurrentIno()))
+
+		sleep := exec.Command("/bin/sleep", "1h")
+		Start(timens, sleep)
+		defer func() {
+			if err := sleep.Process.Kill(); err == nil {
+				_ = sleep.Wait()
+			}
+		}()
+		Expect(Ino(fmt.Sprintf("/proc/%d/ns/time", sleep.Process.Pid))).To(Equal(Ino(timens)))
+
+		var out bytes.Buffer
+		uptime := exec.Command("/bin/cat", "/proc/uptime")
+		uptime.Stdout = &out
+		Run(timens, uptime)
+		uptimeSecs := Successful(strconv.ParseFloat(strings.Fields(out.String())[0], 64))
+		Expect(time.Duration(uptimeSecs * float64(time.Second))).To(BeNumerically(">=", ages))
+	})
+
+})
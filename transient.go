@@ -17,6 +17,7 @@ package spacetest
 import (
 	"fmt"
 	"runtime"
+	"slices"
 
 	"golang.org/x/sys/unix"
 
@@ -64,35 +65,63 @@ import (
 // current OS-level thread with the original (parent) PID or time namespace
 // after creating and switching into a new child PID or time namespace; the
 // returned cleanup function would fail and purposely trigger a panic.
+//
+// EnterTransient is a thin wrapper around [EnterTransientE], failing the
+// current test in case EnterTransientE returns an error.
 func EnterTransient(typ int) func() {
 	GinkgoHelper()
 
+	leave, err := EnterTransientE(typ)
+	Expect(err).NotTo(HaveOccurred())
+	return func() {
+		if err := leave(); err != nil {
+			panic(fmt.Sprintf("leaving from EnterTransient: %s", err.Error()))
+		}
+	}
+}
+
+// EnterTransientE works like [EnterTransient], but instead of failing the
+// current test it returns an error in case the new namespace cannot be created
+// and entered. EnterTransientE thus can also be used outside Ginkgo tests.
+//
+// The returned leave function needs to be called in order to switch the
+// calling go routine and its locked OS-level thread back into the original
+// namespace. If this fails, leave returns an error and leaves the calling go
+// routine locked to its now tainted OS-level thread.
+func EnterTransientE(typ int) (leave func() error, err error) {
 	name := Name(typ)
-	Expect(typ).To(BeElementOf([]int{
+	if !slices.Contains([]int{
 		unix.CLONE_NEWCGROUP,
 		unix.CLONE_NEWIPC,
 		unix.CLONE_NEWNET,
 		unix.CLONE_NEWPID,
 		unix.CLONE_NEWUTS,
-	}), "unsupported type %s", name)
+	}, typ) {
+		return nil, fmt.Errorf("unsupported type %s", name)
+	}
 
 	runtime.LockOSThread()
 
-	callersNamespace, err := unix.Open("/proc/thread-self/ns/"+name, unix.O_RDONLY, 0)
-	Expect(err).NotTo(HaveOccurred(),
-		"cannot determine current %s namespace from procfs", name)
-	Expect(unix.Unshare(typ)).To(Succeed(),
-		"cannot create new %s namespace", Name(typ))
+	callersNamespace, err := unix.Open("/proc/thread-self/ns/"+name, unix.O_RDONLY|unix.O_CLOEXEC, 0)
+	if err != nil {
+		runtime.UnlockOSThread()
+		return nil, fmt.Errorf("cannot determine current %s namespace from procfs: %w",
+			name, err)
+	}
+	if err := unix.Unshare(typ); err != nil {
+		_ = unix.Close(callersNamespace)
+		runtime.UnlockOSThread()
+		return nil, fmt.Errorf("cannot create new %s namespace: %w", name, err)
+	}
 
-	// Our cleanup cannot be DeferCleanup'ed, because we need to restore the current
-	// locked go routine, so that the defer rollback sequence is kept correct.
-	return func() {
+	return func() error {
 		if err := unix.Setns(callersNamespace, typ); err != nil {
-			panic(fmt.Sprintf("leaving from EnterTransient: cannot restore original %s namespace, reason: %s", name, err.Error()))
+			return fmt.Errorf("cannot restore original %s namespace, reason: %w", name, err)
 		}
 		_ = unix.Close(callersNamespace)
 		runtime.UnlockOSThread()
-	}
+		return nil
+	}, nil
 }
 
 // NewTransient creates a new Linux kernel namespace of the specified type, but
@@ -102,8 +131,17 @@ func EnterTransient(typ int) func() {
 //   - unix.CLONE_NEWCGROUP,
 //   - unix.CLONE_NEWIPC,
 //   - unix.CLONE_NEWNET,
+//   - unix.CLONE_NEWPID,
 //   - unix.CLONE_NEWUTS.
 //
+// For PID namespaces, the returned file descriptor references the new PID
+// namespace, which doesn't contain any processes yet. The first process created
+// in it, for instance, using [StartPID1] or by starting a command from inside
+// [Execute], becomes its PID 1. As Linux only hands out references to PID
+// namespaces without any process via pidfds, creating PID namespaces needs
+// Linux 6.11+; on older kernels, NewTransientE returns an error wrapping
+// [errors.ErrUnsupported].
+//
 // For mount namespaces (unix.CLONE_NEWNS) you will need to use the mount
 // namespace-specific [github.com/thediveo/spacetest/mntns.NewTransient]
 // instead.
@@ -114,43 +152,108 @@ func EnterTransient(typ int) func() {
 //
 // When NewTransient returns, the caller's Go routine is in the same OS-level
 // thread lock/unlock state as before the call.
+//
+// NewTransient is a thin wrapper around [NewTransientNamespace], returning the
+// file descriptor of the new Namespace.
 func NewTransient(typ int) int {
 	GinkgoHelper()
 
+	return NewTransientNamespace(typ).Fd()
+}
+
+// NewTransientNamespace creates a new Linux kernel namespace of the specified
+// type, but doesn't enter it. Instead, it returns a [Namespace] referencing the
+// newly created namespace. Please see [NewTransient] for the supported types of
+// namespaces.
+//
+// Additionally to creating a new namespace, NewTransientNamespace also
+// schedules a Ginkgo deferred cleanup in order to close the returned Namespace.
+func NewTransientNamespace(typ int) *Namespace {
+	GinkgoHelper()
+
+	ns, err := NewTransientNamespaceE(typ)
+	Expect(err).NotTo(HaveOccurred())
+	DeferCleanup(func() { _ = ns.Close() })
+	return ns
+}
+
+// NewTransientE works like [NewTransient], but instead of failing the current
+// test it returns an error in case the new namespace cannot be created.
+// NewTransientE thus can also be used outside Ginkgo tests. As NewTransientE
+// doesn't schedule any cleanup, the caller is responsible for closing the
+// returned file descriptor.
+func NewTransientE(typ int) (int, error) {
+	ns, err := NewTransientNamespaceE(typ)
+	if err != nil {
+		return -1, err
+	}
+	return ns.Fd(), nil
+}
+
+// NewTransientNamespaceE works like [NewTransientNamespace], but instead of
+// failing the current test it returns an error in case the new namespace cannot
+// be created. NewTransientNamespaceE thus can also be used outside Ginkgo
+// tests. As NewTransientNamespaceE doesn't schedule any cleanup, the caller is
+// responsible for closing the returned Namespace.
+func NewTransientNamespaceE(typ int) (*Namespace, error) {
 	name := Name(typ)
-	Expect(typ).To(BeElementOf([]int{
+	if !slices.Contains([]int{
 		unix.CLONE_NEWCGROUP,
 		unix.CLONE_NEWIPC,
 		unix.CLONE_NEWNET,
+		unix.CLONE_NEWPID,
 		unix.CLONE_NEWUTS,
-	}), "unsupported type %s", name)
+	}, typ) {
+		return nil, fmt.Errorf("unsupported type %s", name)
+	}
+	if typ == unix.CLONE_NEWPID {
+		// Unsharing only changes the PID namespace for children of the
+		// unsharing thread, so we leave this to a throw-away thread that
+		// cannot accidentally fork processes into the new PID namespace.
+		return newTransientPIDNamespace()
+	}
 
-	// if anything below breaks we won't unlock the OS-level thread on purpose
-	// so that it gets thrown away as the unit test fails and unwinds.
 	runtime.LockOSThread()
 
 	// As Linux only allows us to create a new namespace in combination
 	// immediately entering it, we first need to (literally!) get hold on our
 	// current namespace of the specified type, so we can later re-attach our
 	// OS-level thread to it again.
-	callersNamespace, err := unix.Open("/proc/thread-self/ns/"+name, unix.O_RDONLY, 0)
-	Expect(err).NotTo(HaveOccurred(),
-		"cannot determine current %s namespace from procfs", name)
+	callersNamespace, err := unix.Open("/proc/thread-self/ns/"+name, unix.O_RDONLY|unix.O_CLOEXEC, 0)
+	if err != nil {
+		runtime.UnlockOSThread()
+		return nil, fmt.Errorf("cannot determine current %s namespace from procfs: %w",
+			name, err)
+	}
 	defer func() {
 		// make sure to always close the fd to the original namespace as to not
 		// leak it.
 		_ = unix.Close(callersNamespace)
 	}()
 
-	Expect(unix.Unshare(typ)).To(Succeed(),
-		"cannot create new %s namespace", name)
-	newNamespace, err := unix.Open("/proc/thread-self/ns/"+name, unix.O_RDONLY, 0)
-	Expect(err).NotTo(HaveOccurred(),
-		"cannot determine new %s namespace from procfs", name)
-	Expect(unix.Setns(callersNamespace, typ)).To(Succeed(),
-		"cannot switch back into original %s namespace", name)
-	DeferCleanup(func() { _ = unix.Close(newNamespace) })
-
+	if err := unix.Unshare(typ); err != nil {
+		runtime.UnlockOSThread()
+		return nil, fmt.Errorf("cannot create new %s namespace: %w", name, err)
+	}
+	newNamespace, openErr := unix.Open("/proc/thread-self/ns/"+name, unix.O_RDONLY|unix.O_CLOEXEC, 0)
+	if err := unix.Setns(callersNamespace, typ); err != nil {
+		// if switching back breaks we won't unlock the OS-level thread on
+		// purpose so that it gets thrown away when its go routine finishes.
+		if openErr == nil {
+			_ = unix.Close(newNamespace)
+		}
+		return nil, fmt.Errorf("cannot switch back into original %s namespace: %w",
+			name, err)
+	}
 	runtime.UnlockOSThread()
-	return newNamespace
+	if openErr != nil {
+		return nil, fmt.Errorf("cannot determine new %s namespace from procfs: %w",
+			name, openErr)
+	}
+	ns, err := NewNamespace(newNamespace)
+	if err != nil {
+		_ = unix.Close(newNamespace)
+		return nil, err
+	}
+	return ns, nil
 }
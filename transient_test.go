@@ -132,4 +132,44 @@ var _ = Describe("transient namespaces", Ordered, func() {
 
 	})
 
+	When("using the error-returning core", func() {
+
+		It("rejects unsupported types", func() {
+			nsfd, err := NewTransientE(unix.CLONE_NEWNS)
+			Expect(err).To(MatchError("unsupported type mnt"))
+			Expect(nsfd).To(Equal(-1))
+			leave, err := EnterTransientE(unix.CLONE_NEWTIME)
+			Expect(err).To(MatchError("unsupported type time"))
+			Expect(leave).To(BeNil())
+		})
+
+		It("creates a new namespace owned by the caller", func() {
+			nsfd := Successful(NewTransientE(unix.CLONE_NEWNET))
+			defer func() { Expect(unix.Close(nsfd)).To(Succeed()) }()
+			Expect(nsfd).To(BeNamespaceOfType(unix.CLONE_NEWNET))
+			Expect(nsfd).NotTo(BeSameNamespaceAs("/proc/self/ns/net"))
+		})
+
+		It("enters and leaves a new namespace", func() {
+			runtime.LockOSThread()
+			defer runtime.UnlockOSThread()
+
+			origIno := CurrentIno(unix.CLONE_NEWUTS)
+			leave := Successful(EnterTransientE(unix.CLONE_NEWUTS))
+			Expect(CurrentIno(unix.CLONE_NEWUTS)).NotTo(Equal(origIno))
+			Expect(leave()).To(Succeed())
+			Expect(CurrentIno(unix.CLONE_NEWUTS)).To(Equal(origIno))
+		})
+
+		It("reports failing to leave", func() {
+			runtime.LockOSThread() // this thread will be tainted and must be dropped at the end.
+
+			leave := Successful(EnterTransientE(unix.CLONE_NEWNET))
+			Expect(caps.SetForThisTask(caps.TaskCapabilities{})).To(Succeed())
+			Expect(leave()).To(MatchError(
+				ContainSubstring("cannot restore original net namespace")))
+		})
+
+	})
+
 })
@@ -0,0 +1,352 @@
+// Copyright 2025 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spacetest
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"slices"
+	"sync"
+
+	"golang.org/x/sys/unix"
+
+	. "github.com/onsi/ginkgo/v2" //nolint:staticcheck // ST1001 rule does not apply
+	. "github.com/onsi/gomega"    //nolint:staticcheck // ST1001 rule does not apply
+)
+
+// setTypes lists the types of namespaces supported by [NewTransientSet] and
+// [EnterTransientSet], in the order in which they are entered.
+var setTypes = []int{
+	unix.CLONE_NEWCGROUP,
+	unix.CLONE_NEWIPC,
+	unix.CLONE_NEWNS,
+	unix.CLONE_NEWNET,
+	unix.CLONE_NEWUTS,
+}
+
+// TransientSet contains open file descriptors (>0) referencing a set of new
+// transient namespaces. A zero file descriptor value indicates that no
+// namespace of that particular type was requested and created.
+//
+// If a new mount namespace was requested, ProcfsRoot additionally contains the
+// procfs path of the root directory of the idle OS-level thread keeping the
+// mount namespace alive, such as “/proc/42/root”.
+type TransientSet struct {
+	Cgroup, IPC, Mnt, Net, UTS int
+	ProcfsRoot                 string
+}
+
+// fd returns a pointer to the file descriptor field for the specified type of
+// namespace.
+func (s *TransientSet) fd(typ int) *int {
+	switch typ {
+	case unix.CLONE_NEWCGROUP:
+		return &s.Cgroup
+	case unix.CLONE_NEWIPC:
+		return &s.IPC
+	case unix.CLONE_NEWNS:
+		return &s.Mnt
+	case unix.CLONE_NEWNET:
+		return &s.Net
+	default:
+		return &s.UTS
+	}
+}
+
+// SetOption configures a set of transient namespaces created by, for instance,
+// [NewTransientSet] and [EnterTransientSet].
+type SetOption func(*setOptions)
+
+type setOptions struct {
+	mountSetup func() error
+}
+
+// newSetOptions returns the options resulting from applying the passed options
+// to the default options.
+func newSetOptions(opts ...SetOption) setOptions {
+	o := setOptions{mountSetup: remountPrivate}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// WithMountSetup sets up a new mount namespace in a set using the passed setup
+// function, instead of remounting “/” with private mount point propagation.
+// The setup function gets called on the OS-level thread that has just been
+// attached to the new mount namespace. For sets without a new mount namespace,
+// the setup function is ignored.
+//
+// Use [github.com/thediveo/spacetest/mntns.ForTransientSet] in order to apply
+// mount point propagation options to the new mount namespace in a set.
+func WithMountSetup(setup func() error) SetOption {
+	return func(o *setOptions) { o.mountSetup = setup }
+}
+
+// remountPrivate remounts root in the calling thread's mount namespace to
+// ensure that later mount point manipulations do not propagate back into our
+// host, trashing it.
+func remountPrivate() error {
+	if err := unix.Mount("none", "/", "", unix.MS_REC|unix.MS_PRIVATE, ""); err != nil {
+		return fmt.Errorf("cannot change / mount propagation to private: %w", err)
+	}
+	return nil
+}
+
+// close closes all open file descriptors of this set.
+func (s *TransientSet) close() {
+	for _, typ := range setTypes {
+		if fd := s.fd(typ); *fd > 0 {
+			_ = unix.Close(*fd)
+			*fd = 0
+		}
+	}
+}
+
+// NewTransientSet creates a set of new namespaces of the specified OR'ed
+// types, such as unix.CLONE_NEWNET|unix.CLONE_NEWUTS, all at once, but doesn't
+// enter them. Instead, it returns a [TransientSet] with file descriptors
+// referencing the new namespaces. NewTransientSet supports the following types
+// of namespaces:
+//   - unix.CLONE_NEWCGROUP,
+//   - unix.CLONE_NEWIPC,
+//   - unix.CLONE_NEWNS,
+//   - unix.CLONE_NEWNET,
+//   - unix.CLONE_NEWUTS.
+//
+// When the set includes a new mount namespace, it is kept alive by an idle
+// OS-level thread, with “/” remounted with private mount point propagation,
+// unless specified otherwise using [WithMountSetup]; please see also
+// [github.com/thediveo/spacetest/mntns.NewTransient].
+//
+// NewTransientSet schedules a Ginkgo deferred cleanup in order to close the
+// file descriptors and to terminate the idle OS-level thread, if any. The caller
+// thus must not close the file descriptors returned.
+func NewTransientSet(types int, opts ...SetOption) TransientSet {
+	GinkgoHelper()
+
+	set, release, err := NewTransientSetE(types, opts...)
+	Expect(err).NotTo(HaveOccurred())
+	DeferCleanup(release)
+	return set
+}
+
+// NewTransientSetE works like [NewTransientSet], but instead of failing the
+// current test it returns an error in case the new namespaces cannot be
+// created. NewTransientSetE thus can also be used outside Ginkgo tests.
+//
+// Instead of scheduling any cleanup, NewTransientSetE returns a release
+// function that the caller must call in order to close the returned file
+// descriptors and to terminate the idle OS-level thread, if any. Calling the
+// release function more than once is safe.
+func NewTransientSetE(types int, opts ...SetOption) (set TransientSet, release func(), err error) {
+	if err := checkSetTypes(types); err != nil {
+		return TransientSet{}, nil, err
+	}
+	o := newSetOptions(opts...)
+
+	// closing the done channel tells the Go routine we will kick off next to
+	// call it a day and terminate; only for mount namespaces this Go routine
+	// needs to idle around until then.
+	done := make(chan struct{})
+	type details struct {
+		set TransientSet
+		err error
+	}
+	readyCh := make(chan details)
+	go func() {
+		// never unlock, as this thread is going to be tainted; this includes
+		// the error paths below, so that a partially set up thread gets
+		// thrown away when this go routine finishes.
+		runtime.LockOSThread()
+		defer close(readyCh)
+
+		var set TransientSet
+		flags := types
+		if types&unix.CLONE_NEWNS != 0 {
+			flags |= unix.CLONE_FS
+		}
+		if err := unix.Unshare(flags); err != nil {
+			readyCh <- details{err: fmt.Errorf("cannot create new namespaces: %w", err)}
+			return
+		}
+		if types&unix.CLONE_NEWNS != 0 {
+			if err := o.mountSetup(); err != nil {
+				readyCh <- details{err: err}
+				return
+			}
+			set.ProcfsRoot = fmt.Sprintf("/proc/%d/root", unix.Gettid())
+		}
+		for _, typ := range setTypes {
+			if types&typ == 0 {
+				continue
+			}
+			fd, err := unix.Open("/proc/thread-self/ns/"+Name(typ), unix.O_RDONLY|unix.O_CLOEXEC, 0)
+			if err != nil {
+				set.close()
+				readyCh <- details{err: fmt.Errorf("cannot determine new %s namespace from procfs: %w",
+					Name(typ), err)}
+				return
+			}
+			*set.fd(typ) = fd
+		}
+		readyCh <- details{set: set}
+
+		if types&unix.CLONE_NEWNS != 0 {
+			<-done // ...idle around, then fall off the discworld...
+		}
+	}()
+	d := <-readyCh
+	if d.err != nil {
+		close(done)
+		return TransientSet{}, nil, d.err
+	}
+	var once sync.Once
+	return d.set, func() {
+		once.Do(func() {
+			d.set.close()
+			close(done)
+		})
+	}, nil
+}
+
+// EnterTransientSet creates and enters a set of new namespaces of the specified
+// OR'ed types all at once, returning a function that needs to be defer'ed in
+// order to switch the calling go routine and its locked OS-level thread back
+// into its original namespaces, in reverse order. For instance:
+//
+//	defer spacetest.EnterTransientSet(unix.CLONE_NEWNET|unix.CLONE_NEWUTS)()
+//
+// Please see [NewTransientSet] for the supported types of namespaces. When the
+// set includes a new mount namespace, the OS-level thread won't be unlocked
+// when switching back, as we cannot undo unsharing filesystem attributes.
+//
+// In case the caller cannot be switched back correctly, the defer'ed cleanup
+// function will panic with an error description detailing the reason.
+//
+// EnterTransientSet is a thin wrapper around [EnterTransientSetE], failing the
+// current test in case EnterTransientSetE returns an error.
+func EnterTransientSet(types int, opts ...SetOption) func() {
+	GinkgoHelper()
+
+	leave, err := EnterTransientSetE(types, opts...)
+	Expect(err).NotTo(HaveOccurred())
+	return func() {
+		if err := leave(); err != nil {
+			panic(fmt.Sprintf("leaving from EnterTransientSet: %s", err.Error()))
+		}
+	}
+}
+
+// EnterTransientSetE works like [EnterTransientSet], but instead of failing the
+// current test it returns an error in case the new namespaces cannot be
+// created and entered. EnterTransientSetE thus can also be used outside Ginkgo
+// tests.
+//
+// The returned leave function needs to be called in order to switch the
+// calling go routine and its locked OS-level thread back into the original
+// namespaces. If this fails, leave returns an error and leaves the calling go
+// routine locked to its now tainted OS-level thread.
+func EnterTransientSetE(types int, opts ...SetOption) (leave func() error, err error) {
+	if err := checkSetTypes(types); err != nil {
+		return nil, err
+	}
+	o := newSetOptions(opts...)
+
+	runtime.LockOSThread()
+
+	var callersNamespaces []int
+	closeCallers := func() {
+		for _, fd := range callersNamespaces {
+			_ = unix.Close(fd)
+		}
+	}
+	for _, typ := range setTypes {
+		if types&typ == 0 {
+			continue
+		}
+		fd, err := unix.Open("/proc/thread-self/ns/"+Name(typ), unix.O_RDONLY|unix.O_CLOEXEC, 0)
+		if err != nil {
+			closeCallers()
+			runtime.UnlockOSThread()
+			return nil, fmt.Errorf("cannot determine current %s namespace from procfs: %w",
+				Name(typ), err)
+		}
+		callersNamespaces = append(callersNamespaces, fd)
+	}
+
+	flags := types
+	if types&unix.CLONE_NEWNS != 0 {
+		flags |= unix.CLONE_FS
+	}
+	if err := unix.Unshare(flags); err != nil {
+		// unshare(2) either unshares all requested attributes and namespaces
+		// or none of them, so a failed unshare leaves the OS-level thread
+		// untainted and we can safely unlock it again.
+		closeCallers()
+		runtime.UnlockOSThread()
+		return nil, fmt.Errorf("cannot create new namespaces: %w", err)
+	}
+
+	restore := func() error {
+		defer closeCallers()
+		var errs []error
+		for _, fd := range slices.Backward(callersNamespaces) {
+			if err := unix.Setns(fd, 0); err != nil {
+				typename := "unknown"
+				if typ, err := typeOfFd(fd); err == nil {
+					typename = Name(typ)
+				}
+				errs = append(errs, fmt.Errorf("cannot restore original %s namespace, reason: %w",
+					typename, err))
+			}
+		}
+		if len(errs) != 0 {
+			return errors.Join(errs...)
+		}
+		if types&unix.CLONE_NEWNS == 0 {
+			runtime.UnlockOSThread()
+		}
+		return nil
+	}
+
+	if types&unix.CLONE_NEWNS != 0 {
+		if err := o.mountSetup(); err != nil {
+			// restore doesn't unlock the OS-level thread, as we cannot undo
+			// unsharing the filesystem attributes.
+			_ = restore()
+			return nil, err
+		}
+	}
+
+	return restore, nil
+}
+
+// checkSetTypes returns an error if the passed OR'ed namespace types are empty
+// or contain types not supported by transient namespace sets.
+func checkSetTypes(types int) error {
+	supported := 0
+	for _, typ := range setTypes {
+		supported |= typ
+	}
+	if types == 0 {
+		return errors.New("no namespace types specified")
+	}
+	if types&^supported != 0 {
+		return fmt.Errorf("unsupported namespace types %#x", types&^supported)
+	}
+	return nil
+}
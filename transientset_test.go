@@ -0,0 +1,140 @@
+// Copyright 2025 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spacetest
+
+import (
+	"errors"
+	"os"
+	"runtime"
+
+	"golang.org/x/sys/unix"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/thediveo/caps"
+	. "github.com/thediveo/fdooze"
+	. "github.com/thediveo/success"
+)
+
+var _ = Describe("transient namespace sets", Ordered, func() {
+
+	BeforeAll(func() {
+		if os.Getuid() != 0 {
+			Skip("needs root")
+		}
+	})
+
+	BeforeEach(func() {
+		goodfds := Filedescriptors()
+		DeferCleanup(func() {
+			Expect(Filedescriptors()).NotTo(HaveLeakedFds(goodfds))
+		})
+	})
+
+	It("rejects unsupported types", func() {
+		Expect(NewTransientSetE(0)).Error().To(MatchError("no namespace types specified"))
+		Expect(NewTransientSetE(unix.CLONE_NEWNET | unix.CLONE_NEWUSER)).Error().To(
+			MatchError(ContainSubstring("unsupported namespace types")))
+		Expect(EnterTransientSetE(unix.CLONE_NEWPID)).Error().To(
+			MatchError(ContainSubstring("unsupported namespace types")))
+	})
+
+	It("creates multiple namespaces at once", func() {
+		set := NewTransientSet(unix.CLONE_NEWNET | unix.CLONE_NEWUTS | unix.CLONE_NEWIPC)
+		Expect(set.Net).To(And(
+			BeNamespaceOfType(unix.CLONE_NEWNET),
+			Not(BeSameNamespaceAs("/proc/self/ns/net"))))
+		Expect(set.UTS).To(And(
+			BeNamespaceOfType(unix.CLONE_NEWUTS),
+			Not(BeSameNamespaceAs("/proc/self/ns/uts"))))
+		Expect(set.IPC).To(And(
+			BeNamespaceOfType(unix.CLONE_NEWIPC),
+			Not(BeSameNamespaceAs("/proc/self/ns/ipc"))))
+		Expect(set.Cgroup).To(BeZero())
+		Expect(set.Mnt).To(BeZero())
+		Expect(set.ProcfsRoot).To(BeEmpty())
+	})
+
+	It("creates a new mount namespace kept alive by an idler", func() {
+		set := NewTransientSet(unix.CLONE_NEWNS | unix.CLONE_NEWNET)
+		Expect(set.Mnt).To(And(
+			BeNamespaceOfType(unix.CLONE_NEWNS),
+			Not(BeSameNamespaceAs("/proc/self/ns/mnt"))))
+		Expect(set.ProcfsRoot).To(MatchRegexp(`^/proc/\d+/root$`))
+		Expect(set.ProcfsRoot).To(BeADirectory())
+		Execute(func() {
+			Expect(CurrentIno(unix.CLONE_NEWNET)).To(Equal(Ino(set.Net, unix.CLONE_NEWNET)))
+		}, Fd(set.Mnt), Fd(set.Net))
+	})
+
+	It("sets up a new mount namespace using a custom setup", func() {
+		var mntino uint64
+		set := NewTransientSet(unix.CLONE_NEWNS|unix.CLONE_NEWNET, WithMountSetup(func() error {
+			mntino = CurrentIno(unix.CLONE_NEWNS)
+			return nil
+		}))
+		Expect(mntino).To(Equal(Ino(set.Mnt, unix.CLONE_NEWNS)))
+
+		Expect(NewTransientSetE(unix.CLONE_NEWNS, WithMountSetup(func() error {
+			return errors.New("D'OH!")
+		}))).Error().To(MatchError("D'OH!"))
+	})
+
+	It("releases a set only once", func() {
+		set, release, err := NewTransientSetE(unix.CLONE_NEWNET | unix.CLONE_NEWNS)
+		Expect(err).NotTo(HaveOccurred())
+		release()
+		Expect(unix.Fstat(set.Net, &unix.Stat_t{})).To(MatchError(unix.EBADF))
+		Expect(release).NotTo(Panic())
+	})
+
+	It("enters and leaves multiple namespaces at once", func() {
+		runtime.LockOSThread()
+		defer runtime.UnlockOSThread()
+
+		orignet := CurrentIno(unix.CLONE_NEWNET)
+		origuts := CurrentIno(unix.CLONE_NEWUTS)
+		leave := EnterTransientSet(unix.CLONE_NEWNET | unix.CLONE_NEWUTS)
+		Expect(CurrentIno(unix.CLONE_NEWNET)).NotTo(Equal(orignet))
+		Expect(CurrentIno(unix.CLONE_NEWUTS)).NotTo(Equal(origuts))
+		leave()
+		Expect(CurrentIno(unix.CLONE_NEWNET)).To(Equal(orignet))
+		Expect(CurrentIno(unix.CLONE_NEWUTS)).To(Equal(origuts))
+	})
+
+	It("enters and leaves a set including a mount namespace", func() {
+		runtime.LockOSThread() // stays locked as CLONE_FS cannot be undone.
+
+		origmnt := CurrentIno(unix.CLONE_NEWNS)
+		orignet := CurrentIno(unix.CLONE_NEWNET)
+		leave := Successful(EnterTransientSetE(unix.CLONE_NEWNS | unix.CLONE_NEWNET))
+		Expect(CurrentIno(unix.CLONE_NEWNS)).NotTo(Equal(origmnt))
+		Expect(CurrentIno(unix.CLONE_NEWNET)).NotTo(Equal(orignet))
+		Expect(leave()).To(Succeed())
+		Expect(CurrentIno(unix.CLONE_NEWNS)).To(Equal(origmnt))
+		Expect(CurrentIno(unix.CLONE_NEWNET)).To(Equal(orignet))
+	})
+
+	It("panics when unable to restore the previously attached namespaces", func() {
+		runtime.LockOSThread() // this thread will be tainted and must be dropped at the end.
+
+		leave := EnterTransientSet(unix.CLONE_NEWNET | unix.CLONE_NEWUTS)
+		Expect(caps.SetForThisTask(caps.TaskCapabilities{})).To(Succeed())
+		Expect(leave).To(PanicWith(And(
+			ContainSubstring("cannot restore original net namespace"),
+			ContainSubstring("cannot restore original uts namespace"))))
+	})
+
+})
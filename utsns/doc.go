@@ -0,0 +1,32 @@
+/*
+Package utsns supports running unit tests in separated transient UTS
+namespaces, handling cleanup and error checking automatically.
+
+UTS namespaces isolate the host name and the (NIS) domain name. Tests of code
+reporting or acting on the host name thus can set these names to well-known
+values without trashing the host's names.
+
+# Usage
+
+	import "github.com/thediveo/spacetest/utsns"
+
+	It("tests something inside a transient UTS namespace", func() {
+	  defer utsns.EnterTransient()() // !!! double ()()
+	  utsns.SetHostname("zaphod")
+	  // ...
+	})
+
+Alternatively, without entering the new UTS namespace:
+
+	It("tests something inside a transient UTS namespace", func() {
+	  utsnsfd := utsns.NewTransient()
+	  utsns.Execute(utsnsfd, func() {
+	    utsns.SetHostname("zaphod")
+	    utsns.SetDomainname("heartofgold")
+	  })
+	})
+
+[SetHostname] and [SetDomainname] refuse to work while the calling OS-level
+thread is still attached to the process's original UTS namespace.
+*/
+package utsns
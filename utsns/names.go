@@ -0,0 +1,74 @@
+// Copyright 2025 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package utsns
+
+import (
+	"golang.org/x/sys/unix"
+
+	. "github.com/onsi/ginkgo/v2" //nolint:staticcheck // ST1001 rule does not apply
+	. "github.com/onsi/gomega"    //nolint:staticcheck // ST1001 rule does not apply
+)
+
+// SetHostname sets the host name of the UTS namespace the calling OS-level
+// thread is currently attached to. The caller must be in a new and transient
+// UTS namespace, otherwise SetHostname fails the current test.
+func SetHostname(name string) {
+	GinkgoHelper()
+
+	ensureTransient()
+	Expect(unix.Sethostname([]byte(name))).To(Succeed(),
+		"cannot set host name to %q", name)
+}
+
+// SetDomainname sets the (NIS) domain name of the UTS namespace the calling
+// OS-level thread is currently attached to. The caller must be in a new and
+// transient UTS namespace, otherwise SetDomainname fails the current test.
+func SetDomainname(name string) {
+	GinkgoHelper()
+
+	ensureTransient()
+	Expect(unix.Setdomainname([]byte(name))).To(Succeed(),
+		"cannot set domain name to %q", name)
+}
+
+// Hostname returns the host name of the UTS namespace the calling OS-level
+// thread is currently attached to.
+func Hostname() string {
+	GinkgoHelper()
+
+	var uts unix.Utsname
+	Expect(unix.Uname(&uts)).To(Succeed(), "cannot determine host name")
+	return unix.ByteSliceToString(uts.Nodename[:])
+}
+
+// Domainname returns the (NIS) domain name of the UTS namespace the calling
+// OS-level thread is currently attached to.
+func Domainname() string {
+	GinkgoHelper()
+
+	var uts unix.Utsname
+	Expect(unix.Uname(&uts)).To(Succeed(), "cannot determine domain name")
+	return unix.ByteSliceToString(uts.Domainname[:])
+}
+
+// ensureTransient fails the current test if the calling OS-level thread is
+// still attached to the process's original UTS namespace, as otherwise we would
+// change the names of the host.
+func ensureTransient() {
+	GinkgoHelper()
+
+	Expect(CurrentIno()).NotTo(Equal(Ino("/proc/self/ns/uts")),
+		"current UTS namespace must not be the process's original UTS namespace")
+}
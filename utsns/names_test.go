@@ -0,0 +1,59 @@
+// Copyright 2025 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package utsns
+
+import (
+	"os"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("host and domain names", func() {
+
+	BeforeEach(func() {
+		if os.Getuid() != 0 {
+			Skip("needs root")
+		}
+	})
+
+	It("rejects setting names in the original UTS namespace", func() {
+		Expect(InterceptGomegaFailure(func() {
+			SetHostname("zaphod")
+		})).To(MatchError(
+			ContainSubstring("current UTS namespace must not be the process's original UTS namespace")))
+		Expect(InterceptGomegaFailure(func() {
+			SetDomainname("heartofgold")
+		})).To(MatchError(
+			ContainSubstring("current UTS namespace must not be the process's original UTS namespace")))
+	})
+
+	It("sets host and domain names in a transient UTS namespace", func() {
+		hostname := Hostname()
+		domainname := Domainname()
+
+		utsns := NewTransient()
+		Execute(utsns, func() {
+			SetHostname("zaphod")
+			SetDomainname("heartofgold")
+			Expect(Hostname()).To(Equal("zaphod"))
+			Expect(Domainname()).To(Equal("heartofgold"))
+		})
+
+		Expect(Hostname()).To(Equal(hostname))
+		Expect(Domainname()).To(Equal(domainname))
+	})
+
+})
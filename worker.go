@@ -0,0 +1,133 @@
+// Copyright 2025 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spacetest
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"sync"
+
+	. "github.com/onsi/ginkgo/v2" //nolint:staticcheck // ST1001 rule does not apply
+	. "github.com/onsi/gomega"    //nolint:staticcheck // ST1001 rule does not apply
+)
+
+// Worker executes functions on a long-lived OS-level thread that is
+// permanently attached to a fixed set of namespaces. In contrast to [Execute],
+// a Worker thus doesn't need to switch namespaces in and out for each function
+// executed, making it suitable for running large numbers of small probes
+// inside the same set of namespaces.
+//
+// A Worker's OS-level thread never gets unlocked, so it is thrown away when
+// the Worker is closed.
+type Worker struct {
+	fnCh      chan work
+	closeOnce sync.Once
+	gone      chan struct{}
+}
+
+// work to be executed on a Worker's OS-level thread, passing back any panic
+// raised by fn (or nil).
+type work struct {
+	fn      func()
+	panicCh chan any
+}
+
+// NewWorker returns a new Worker whose OS-level thread is attached to the
+// specified namespace(s), and otherwise to the caller's currently attached
+// namespaces. Same as with [Execute], switching into a different user namespace
+// is not possible.
+//
+// NewWorker schedules a DeferCleanup to close the Worker at the end of the
+// current test. If the Worker's thread cannot be attached to the specified
+// namespaces, NewWorker fails the current test.
+func NewWorker(nsref NamespaceRef, nsrefs ...NamespaceRef) *Worker {
+	GinkgoHelper()
+
+	w, err := NewWorkerE(nsref, nsrefs...)
+	Expect(err).NotTo(HaveOccurred())
+	DeferCleanup(w.Close)
+	return w
+}
+
+// NewWorkerE works like [NewWorker], but returns an error instead of failing
+// the current test. NewWorkerE thus can also be used outside Ginkgo tests. The
+// caller is responsible for closing the returned Worker.
+func NewWorkerE(nsref NamespaceRef, nsrefs ...NamespaceRef) (*Worker, error) {
+	mntnsfd, othernsfds, err := sortOut(append([]NamespaceRef{nsref}, nsrefs...))
+	if errors.Is(err, errUserNamespace) {
+		return nil, fmt.Errorf("cannot create Worker %w", err)
+	} else if err != nil {
+		return nil, err
+	}
+
+	runtime.LockOSThread()
+	pickupfds, err := pickup(othernsfds)
+	runtime.UnlockOSThread()
+	defer closeAll(pickupfds)
+	if err != nil {
+		return nil, err
+	}
+
+	w := &Worker{
+		fnCh: make(chan work),
+		gone: make(chan struct{}),
+	}
+	setupCh := make(chan error)
+	go func() {
+		defer close(w.gone)
+		runtime.LockOSThread() // never unlock, as this thread is going to be tainted
+
+		if err := attach(mntnsfd, append(othernsfds, pickupfds...)); err != nil {
+			setupCh <- err
+			return
+		}
+		close(setupCh)
+
+		for work := range w.fnCh {
+			work.run()
+		}
+	}()
+	if err := <-setupCh; err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// Execute fn synchronously on the Worker's OS-level thread. Panics raised by
+// fn are passed on to the caller. Execute must not be called after the Worker
+// has been closed.
+func (w *Worker) Execute(fn func()) {
+	panicCh := make(chan any)
+	w.fnCh <- work{fn: fn, panicCh: panicCh}
+	if r := <-panicCh; r != nil {
+		panic(r)
+	}
+}
+
+// Close the Worker, waiting for its OS-level thread to terminate. Close can be
+// called multiple times; only the first call actually closes the Worker.
+func (w *Worker) Close() {
+	w.closeOnce.Do(func() { close(w.fnCh) })
+	<-w.gone
+}
+
+// run the work's fn, passing back any panic raised by it.
+func (wk work) run() {
+	defer func() {
+		wk.panicCh <- recover()
+	}()
+	wk.fn()
+}
@@ -0,0 +1,144 @@
+// Copyright 2025 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spacetest
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"golang.org/x/sys/unix"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gleak"
+	. "github.com/thediveo/fdooze"
+)
+
+var _ = Describe("namespace workers", func() {
+
+	BeforeEach(func() {
+		if os.Getuid() != 0 {
+			Skip("needs root")
+		}
+		goodfds := Filedescriptors()
+		goodgos := Goroutines()
+		DeferCleanup(func() {
+			Eventually(Goroutines).Within(2 * time.Second).ProbeEvery(100 * time.Millisecond).
+				ShouldNot(HaveLeaked(goodgos))
+			Expect(Filedescriptors()).NotTo(HaveLeakedFds(goodfds))
+		})
+	})
+
+	It("executes repeatedly on the same thread in the same namespaces", func() {
+		netns := NewTransientNamespace(unix.CLONE_NEWNET)
+		utsns := NewTransientNamespace(unix.CLONE_NEWUTS)
+		w := NewWorker(netns, utsns)
+
+		var tid int
+		w.Execute(func() { tid = unix.Gettid() })
+		for range 3 {
+			w.Execute(func() {
+				Expect(unix.Gettid()).To(Equal(tid))
+				Expect(netns).To(BeCurrentNamespace())
+				Expect(utsns).To(BeCurrentNamespace())
+				Expect("/proc/thread-self/ns/mnt").To(BeSameNamespaceAs("/proc/self/ns/mnt"))
+			})
+		}
+		Expect(netns).NotTo(BeCurrentNamespace())
+	})
+
+	It("executes in a mount namespace", func() {
+		mntns := CurrentNamespace(unix.CLONE_NEWNS)
+		w := NewWorker(mntns)
+		w.Execute(func() {
+			Expect(mntns).To(BeCurrentNamespace())
+		})
+		w.Close()
+		w.Close()
+	})
+
+	It("passes panics on to the caller", func() {
+		w := NewWorker(CurrentNamespace(unix.CLONE_NEWNET))
+		Expect(func() {
+			w.Execute(func() { panic("D'oh!") })
+		}).To(PanicWith("D'oh!"))
+		count := 0
+		w.Execute(func() { count++ })
+		Expect(count).To(Equal(1), "worker didn't survive panic")
+	})
+
+	It("fails when unable to switch namespaces", func() {
+		Expect(NewWorkerE(Fd(-1))).Error().To(
+			MatchError(ContainSubstring("invalid namespace reference")))
+		Expect(NewWorkerE(Fd(Current(unix.CLONE_NEWUSER)))).Error().To(
+			MatchError("cannot create Worker in different user namespace"))
+	})
+
+})
+
+func BenchmarkExecute(b *testing.B) {
+	if os.Getuid() != 0 {
+		b.Skip("needs root")
+	}
+	netnsfd, err := NewTransientE(unix.CLONE_NEWNET)
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer func() { _ = unix.Close(netnsfd) }()
+
+	for b.Loop() {
+		if err := ExecuteE(func() {}, Fd(netnsfd)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkExecuteMount(b *testing.B) {
+	if os.Getuid() != 0 {
+		b.Skip("needs root")
+	}
+	mntnsfd, err := unix.Open("/proc/self/ns/mnt", unix.O_RDONLY|unix.O_CLOEXEC, 0)
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer func() { _ = unix.Close(mntnsfd) }()
+
+	for b.Loop() {
+		if err := ExecuteE(func() {}, Fd(mntnsfd)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkWorker(b *testing.B) {
+	if os.Getuid() != 0 {
+		b.Skip("needs root")
+	}
+	netnsfd, err := NewTransientE(unix.CLONE_NEWNET)
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer func() { _ = unix.Close(netnsfd) }()
+	w, err := NewWorkerE(Fd(netnsfd))
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer w.Close()
+
+	for b.Loop() {
+		w.Execute(func() {})
+	}
+}